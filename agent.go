@@ -0,0 +1,389 @@
+package main
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	gnet "github.com/shirou/gopsutil/v3/net"
+)
+
+// `jarvis agent` serves this machine's collector output over TCP so a
+// dashboard elsewhere can show it on the Fleet page.
+//
+// Every frame starts with an 8-byte header: the magic "JV", the protocol
+// version, the frame type and a big-endian payload length. Payloads are
+// JSON. The client opens with a hello carrying the token; the agent answers
+// with a welcome (or an error frame and a hangup) and then pushes a snapshot
+// every interval until the connection drops.
+
+const (
+	agentMagic           = "JV"
+	agentProtocolVersion = 1
+	agentHeaderSize      = 8
+	maxAgentFrame        = 8 << 20
+	maxHelloFrame        = 4 << 10 // what a client can make the agent allocate before its token checks out
+
+	defaultAgentPort     = "7787"
+	defaultAgentInterval = 2 * time.Second
+
+	// agentHandshakeTimeout bounds how long either side waits for the
+	// hello/welcome exchange
+	agentHandshakeTimeout = 10 * time.Second
+)
+
+type frameType byte
+
+const (
+	frameHello frameType = iota + 1
+	frameWelcome
+	frameSnapshot
+	frameError
+)
+
+type agentHello struct {
+	Token  string `json:"token"`
+	Client string `json:"client"`
+}
+
+type agentWelcome struct {
+	Host       string `json:"host"`
+	IntervalMS int64  `json:"interval_ms"`
+}
+
+type agentError struct {
+	Error string `json:"error"`
+}
+
+// agentSnapshot is one collection round on the agent's host
+type agentSnapshot struct {
+	Time      time.Time             `json:"time"`
+	Sample    Sample                `json:"sample"`
+	SampleErr string                `json:"sample_err,omitempty"`
+	Processes []processInfo         `json:"processes"`
+	Disks     []diskInfo            `json:"disks"`
+	Ifaces    []gnet.IOCountersStat `json:"ifaces"`
+	Alerts    []agentAlert          `json:"alerts,omitempty"`
+}
+
+// agentAlert is a condition the agent's host is in right now
+type agentAlert struct {
+	Name     string `json:"name"`     // short badge label, e.g. "CPU" or "PORTS"
+	Severity int    `json:"severity"` // 1-3, as for alertRecord
+}
+
+// alertLevels are what the agent alerts on besides its vitals
+type alertLevels struct {
+	allow      portAllowlist
+	lowBattery float64 // percent
+}
+
+// agentAlerts lists the alerts a host is in after one collection round:
+// vitals over their thresholds, a low battery and exposed services, worked
+// out the same way the dashboard does for its own machine
+func agentAlerts(snap agentSnapshot, conns connectionsMsg, power powerMsg, levels alertLevels) []agentAlert {
+	var alerts []agentAlert
+	vital := func(name string, v, warn, crit float64) {
+		switch {
+		case v >= crit:
+			alerts = append(alerts, agentAlert{name, 3})
+		case v >= warn:
+			alerts = append(alerts, agentAlert{name, 1})
+		}
+	}
+	diskMax := 0.0
+	for _, d := range snap.Disks {
+		diskMax = max(diskMax, d.UsedPercent)
+	}
+	if snap.SampleErr == "" {
+		vital("CPU", snap.Sample.CPUPercent, 75, 90)
+		vital("MEM", snap.Sample.MemUsedPercent, 80, 90)
+	}
+	vital("DISK", diskMax, 80, 90)
+
+	if charge, ok := power.charge(); ok && power.discharging() {
+		switch {
+		case charge*100 <= criticalBattery:
+			alerts = append(alerts, agentAlert{"BATT", 3})
+		case charge*100 <= levels.lowBattery:
+			alerts = append(alerts, agentAlert{"BATT", 2})
+		}
+	}
+	for _, l := range conns.listeners {
+		if l.exposed() && !levels.allow.allows(l) {
+			alerts = append(alerts, agentAlert{"PORTS", 2})
+			break
+		}
+	}
+	return alerts
+}
+
+// versionError reports a frame from a peer speaking another protocol version
+type versionError struct {
+	version byte
+}
+
+func (e versionError) Error() string {
+	return fmt.Sprintf("peer speaks protocol v%d, this build speaks v%d", e.version, agentProtocolVersion)
+}
+
+func writeFrame(w io.Writer, t frameType, v any) error {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if len(payload) > maxAgentFrame {
+		return fmt.Errorf("frame of %d bytes exceeds the %d byte limit", len(payload), maxAgentFrame)
+	}
+	buf := make([]byte, agentHeaderSize, agentHeaderSize+len(payload))
+	copy(buf, agentMagic)
+	buf[2] = agentProtocolVersion
+	buf[3] = byte(t)
+	binary.BigEndian.PutUint32(buf[4:], uint32(len(payload)))
+	_, err = w.Write(append(buf, payload...))
+	return err
+}
+
+// readFrame reads one frame of at most limit bytes. A frame from another
+// protocol version is still consumed whole, so the caller can decode an
+// error frame from it.
+func readFrame(r io.Reader, limit uint32) (frameType, []byte, error) {
+	var hdr [agentHeaderSize]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return 0, nil, err
+	}
+	if string(hdr[:2]) != agentMagic {
+		return 0, nil, errors.New("not a jarvis agent (bad frame magic)")
+	}
+	n := binary.BigEndian.Uint32(hdr[4:])
+	if n > limit {
+		return 0, nil, fmt.Errorf("frame of %d bytes exceeds the %d byte limit", n, limit)
+	}
+	payload := make([]byte, n)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, nil, err
+	}
+	if hdr[2] != agentProtocolVersion {
+		return frameType(hdr[3]), payload, versionError{hdr[2]}
+	}
+	return frameType(hdr[3]), payload, nil
+}
+
+// --- Agent server ---
+
+// agentServer collects on one goroutine and fans snapshots out to every
+// connected dashboard, so CPU percentages cover the collection interval
+// no matter how many clients are attached
+type agentServer struct {
+	token    string
+	host     string
+	interval time.Duration
+	collect  func() agentSnapshot
+
+	mu     sync.Mutex
+	latest []byte // encoded snapshot frame, nil until the first round
+	subs   map[chan []byte]struct{}
+}
+
+func newAgentServer(token, host string, interval time.Duration, collect func() agentSnapshot) *agentServer {
+	return &agentServer{
+		token:    token,
+		host:     host,
+		interval: interval,
+		collect:  collect,
+		subs:     make(map[chan []byte]struct{}),
+	}
+}
+
+// collectLocal gathers a snapshot of this machine
+func collectLocal(src MetricSource, cache *processCache, power *powerReader, levels alertLevels) func() agentSnapshot {
+	conns := &connectionReader{}
+	return func() agentSnapshot {
+		snap := agentSnapshot{Time: time.Now()}
+		var err error
+		if snap.Sample, err = src.Sample(); err != nil {
+			snap.SampleErr = err.Error()
+		}
+		snap.Processes = cache.collect().procs
+		snap.Disks = collectDisks()
+		snap.Ifaces = collectIfaces().stats
+		snap.Alerts = agentAlerts(snap, conns.read(), power.read(), levels)
+		return snap
+	}
+}
+
+// run collects every interval until stop is closed
+func (a *agentServer) run(stop <-chan struct{}) {
+	t := time.NewTicker(a.interval)
+	defer t.Stop()
+	for {
+		a.publish(a.collect())
+		select {
+		case <-stop:
+			return
+		case <-t.C:
+		}
+	}
+}
+
+func (a *agentServer) publish(snap agentSnapshot) {
+	var buf bytes.Buffer
+	if err := writeFrame(&buf, frameSnapshot, snap); err != nil {
+		return
+	}
+	frame := buf.Bytes()
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.latest = frame
+	for ch := range a.subs {
+		// Slow clients skip to the newest snapshot rather than queueing
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- frame
+	}
+}
+
+func (a *agentServer) subscribe() (chan []byte, []byte) {
+	ch := make(chan []byte, 1)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.subs[ch] = struct{}{}
+	return ch, a.latest
+}
+
+func (a *agentServer) unsubscribe(ch chan []byte) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.subs, ch)
+}
+
+// serve accepts dashboards until the listener is closed
+func (a *agentServer) serve(ln net.Listener) error {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return err
+		}
+		go a.handle(conn)
+	}
+}
+
+func (a *agentServer) handle(conn net.Conn) {
+	defer conn.Close()
+
+	conn.SetDeadline(time.Now().Add(agentHandshakeTimeout))
+	t, payload, err := readFrame(conn, maxHelloFrame)
+	var verr versionError
+	switch {
+	case errors.As(err, &verr):
+		writeFrame(conn, frameError, agentError{verr.Error()})
+		return
+	case err != nil:
+		return
+	case t != frameHello:
+		writeFrame(conn, frameError, agentError{"expected hello"})
+		return
+	}
+	var hello agentHello
+	if err := json.Unmarshal(payload, &hello); err != nil {
+		writeFrame(conn, frameError, agentError{"malformed hello"})
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(hello.Token), []byte(a.token)) != 1 {
+		writeFrame(conn, frameError, agentError{"invalid token"})
+		return
+	}
+	if err := writeFrame(conn, frameWelcome, agentWelcome{Host: a.host, IntervalMS: a.interval.Milliseconds()}); err != nil {
+		return
+	}
+	conn.SetDeadline(time.Time{})
+
+	ch, latest := a.subscribe()
+	defer a.unsubscribe(ch)
+
+	// The client never speaks after hello; a read returning means it hung up
+	gone := make(chan struct{})
+	go func() {
+		io.Copy(io.Discard, conn)
+		close(gone)
+	}()
+
+	send := func(frame []byte) bool {
+		conn.SetWriteDeadline(time.Now().Add(a.interval * 3))
+		_, err := conn.Write(frame)
+		return err == nil
+	}
+	if latest != nil && !send(latest) {
+		return
+	}
+	for {
+		select {
+		case <-gone:
+			return
+		case frame := <-ch:
+			if !send(frame) {
+				return
+			}
+		}
+	}
+}
+
+// runAgent implements `jarvis agent`
+func runAgent(args []string) error {
+	fs := flag.NewFlagSet("agent", flag.ExitOnError)
+	listen := fs.String("listen", ":"+defaultAgentPort, "address to accept dashboards on")
+	token := fs.String("token", "", "shared secret dashboards must present (default $JARVIS_AGENT_TOKEN)")
+	interval := fs.Duration("interval", defaultAgentInterval, "how often to collect and push a snapshot")
+	configPath := fs.String("config", "", "config file whose ports allowlist and power settings the alerts use (default "+defaultConfigPath()+")")
+	fs.Parse(args)
+
+	if *token == "" {
+		*token = os.Getenv("JARVIS_AGENT_TOKEN")
+	}
+	if *token == "" {
+		return errors.New("a token is required (--token or $JARVIS_AGENT_TOKEN)")
+	}
+	if *interval <= 0 {
+		return errors.New("--interval must be positive")
+	}
+
+	path, required := *configPath, true
+	if path == "" {
+		path, required = defaultConfigPath(), false
+	}
+	cfg, err := loadConfig(path, required)
+	if err != nil {
+		return err
+	}
+	levels := alertLevels{lowBattery: cfg.Power.lowBattery()}
+	if levels.allow, err = newPortAllowlist(cfg.Ports); err != nil {
+		return err
+	}
+
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+
+	ln, err := net.Listen("tcp", *listen)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("J.A.R.V.I.S. agent for %s listening on %s\n", host, ln.Addr())
+
+	a := newAgentServer(*token, host, *interval, collectLocal(localSource{}, newProcessCache(), newPowerReader(cfg.Power), levels))
+	go a.run(nil)
+	return a.serve(ln)
+}
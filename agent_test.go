@@ -0,0 +1,244 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	gnet "github.com/shirou/gopsutil/v3/net"
+)
+
+// startTestAgent serves canned snapshots on a loopback port
+func startTestAgent(t *testing.T, token string) (*agentServer, string) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	round := 0
+	a := newAgentServer(token, "web-1", 20*time.Millisecond, func() agentSnapshot {
+		round++
+		return agentSnapshot{
+			Time:      testTime.Add(time.Duration(round) * time.Second),
+			Sample:    Sample{CPUPercent: 55, MemUsedPercent: 70, NetBytesRecv: uint64(round) * 2048, NetBytesSent: uint64(round) * 1024},
+			Processes: testProcesses(),
+			Disks:     testDisks(),
+			Ifaces:    []gnet.IOCountersStat{{Name: "eth0", BytesRecv: uint64(round) * 2048}},
+			Alerts:    []agentAlert{{"PORTS", 2}},
+		}
+	})
+	stop := make(chan struct{})
+	t.Cleanup(func() { close(stop) })
+	go a.run(stop)
+	go a.serve(ln)
+	return a, ln.Addr().String()
+}
+
+func TestFrameRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeFrame(&buf, frameHello, agentHello{Token: "abc"}); err != nil {
+		t.Fatal(err)
+	}
+	raw := append([]byte(nil), buf.Bytes()...)
+
+	typ, payload, err := readFrame(&buf, maxAgentFrame)
+	if err != nil || typ != frameHello || string(payload) != `{"token":"abc","client":""}` {
+		t.Fatalf("readFrame = %d %s %v", typ, payload, err)
+	}
+
+	// A frame from a newer protocol is read whole and reported
+	raw[2] = agentProtocolVersion + 1
+	typ, payload, err = readFrame(bytes.NewReader(raw), maxAgentFrame)
+	var verr versionError
+	if !errors.As(err, &verr) || typ != frameHello || len(payload) == 0 {
+		t.Errorf("future version: %d %s %v", typ, payload, err)
+	}
+
+	raw[0] = 'X'
+	if _, _, err := readFrame(bytes.NewReader(raw), maxAgentFrame); err == nil || !strings.Contains(err.Error(), "magic") {
+		t.Errorf("bad magic: err = %v", err)
+	}
+
+	big := make([]byte, agentHeaderSize)
+	copy(big, agentMagic)
+	big[2] = agentProtocolVersion
+	binary.BigEndian.PutUint32(big[4:], maxAgentFrame+1)
+	if _, _, err := readFrame(bytes.NewReader(big), maxAgentFrame); err == nil || !strings.Contains(err.Error(), "limit") {
+		t.Errorf("oversized frame: err = %v", err)
+	}
+}
+
+// waitFor polls cond until it holds or the test times out
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestAgentStreamsSnapshots(t *testing.T) {
+	_, addr := startTestAgent(t, "s3cret")
+
+	f, err := newFleet([]AgentConfig{
+		{Name: "web-1", Addr: addr, Token: "s3cret"},
+		{Name: "intruder", Addr: addr, Token: "guess"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.start()
+	defer f.close()
+
+	web, intruder := f.agents[0], f.agents[1]
+	waitFor(t, "two snapshots", func() bool {
+		st := web.status()
+		return st.State == agentOnline && st.RxRate > 0
+	})
+	st := web.status()
+	if st.Host != "web-1" || st.Sample.CPUPercent != 55 || len(st.Alerts) != 1 || st.Alerts[0].Name != "PORTS" {
+		t.Errorf("status = %+v", st)
+	}
+	// Snapshots are a second apart with 2048 more bytes received in each
+	if st.RxRate != 2048 || st.TxRate != 1024 {
+		t.Errorf("rates = %v/%v, want 2048/1024", st.RxRate, st.TxRate)
+	}
+
+	waitFor(t, "rejection", func() bool { return intruder.status().State == agentRejected })
+	if st := intruder.status(); !strings.Contains(st.Err, "invalid token") || st.HasData {
+		t.Errorf("intruder = %+v", st)
+	}
+
+	// Drilling into the host feeds its snapshot through every panel
+	m := newTestModel(t, 120, 40)
+	m.fleet = f
+	m = step(m, collectFleetCommand(f)())
+	m.setPage(pageFleet)
+	m.scrollPanel(panelFleet, 1)
+	m = step(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.remote != web || m.page != pageOverview {
+		t.Fatalf("Enter on web-1 left remote=%v page=%d", m.remote, m.page)
+	}
+	for _, cmd := range m.backgroundCollectCommands() {
+		m = step(m, cmd())
+	}
+	if len(m.processes) != 3 || len(m.disks) != 2 || m.cpuVal != 0.55 {
+		t.Errorf("remote dashboard: %d processes, %d disks, cpu %v", len(m.processes), len(m.disks), m.cpuVal)
+	}
+	if !strings.Contains(m.View(), "HOST WEB-1") {
+		t.Error("title does not name the remote host")
+	}
+
+	m.openHost(0)
+	if m.remote != nil || len(m.processes) != 0 {
+		t.Errorf("returning to local kept remote=%v, %d processes", m.remote, len(m.processes))
+	}
+}
+
+func TestAgentAlerts(t *testing.T) {
+	allow, _ := newPortAllowlist(&PortsConfig{Allow: []string{"sshd"}})
+	levels := alertLevels{allow: allow, lowBattery: 15}
+
+	snap := agentSnapshot{Sample: Sample{CPUPercent: 94, MemUsedPercent: 82}, Disks: testDisks()}
+	conns := connectionsMsg{listeners: testListeners()}
+	power := powerMsg{batteries: []batteryInfo{{Percent: 12, Status: "Discharging"}}}
+	got := agentAlerts(snap, conns, power, levels)
+	want := []agentAlert{{"CPU", 3}, {"MEM", 1}, {"BATT", 2}, {"PORTS", 2}}
+	if !slices.Equal(got, want) {
+		t.Errorf("alerts = %v, want %v", got, want)
+	}
+
+	// A quiet host with only allowlisted services raises nothing
+	snap = agentSnapshot{Sample: Sample{CPUPercent: 20, MemUsedPercent: 40}}
+	conns = connectionsMsg{listeners: testListeners()[:3]}
+	if got := agentAlerts(snap, conns, powerMsg{}, levels); len(got) != 0 {
+		t.Errorf("quiet host alerts = %v", got)
+	}
+
+	// The card counts the badges it has no room for
+	badges := ansi.Strip(hostAlertBadges(append(want, agentAlert{"DISK", 3}), fleetCardWidth-2))
+	if badges != " CPU   DISK   BATT  +2" {
+		t.Errorf("card badges = %q", badges)
+	}
+}
+
+func TestAgentRejectsOtherVersions(t *testing.T) {
+	_, addr := startTestAgent(t, "s3cret")
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	var buf bytes.Buffer
+	writeFrame(&buf, frameHello, agentHello{Token: "s3cret"})
+	hello := buf.Bytes()
+	hello[2] = agentProtocolVersion + 1
+	conn.Write(hello)
+
+	typ, payload, err := readFrame(conn, maxAgentFrame)
+	if err != nil || typ != frameError || !strings.Contains(string(payload), "protocol v2") {
+		t.Errorf("reply = %d %s %v", typ, payload, err)
+	}
+}
+
+func TestAgentLimitsHello(t *testing.T) {
+	_, addr := startTestAgent(t, "s3cret")
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	// A header announcing a large hello is refused before any payload
+	hdr := make([]byte, agentHeaderSize)
+	copy(hdr, agentMagic)
+	hdr[2] = agentProtocolVersion
+	hdr[3] = byte(frameHello)
+	binary.BigEndian.PutUint32(hdr[4:], maxHelloFrame+1)
+	conn.Write(hdr)
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if n, err := conn.Read(make([]byte, 1)); err != io.EOF {
+		t.Errorf("oversized hello: read %d bytes, err = %v; want hangup", n, err)
+	}
+}
+
+func TestFleetConfig(t *testing.T) {
+	f, err := newFleet([]AgentConfig{{Addr: "10.0.0.5", Token: "t"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg := f.agents[0].cfg; cfg.Addr != "10.0.0.5:"+defaultAgentPort || cfg.Name != "10.0.0.5" {
+		t.Errorf("defaults = %+v", cfg)
+	}
+
+	for _, tc := range []struct {
+		cfgs []AgentConfig
+		err  string
+	}{
+		{[]AgentConfig{{Name: "a"}}, "addr is required"},
+		{[]AgentConfig{{Name: "a", Addr: "x"}}, "token is required"},
+		{[]AgentConfig{{Name: "a", Addr: "x", Token: "t"}, {Name: "a", Addr: "y", Token: "t"}}, "duplicate name"},
+	} {
+		if _, err := newFleet(tc.cfgs); err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("%+v: err = %v, want %q", tc.cfgs, err, tc.err)
+		}
+	}
+}
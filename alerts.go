@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// maxAlertHistory bounds the number of alerts kept for the history overlay
+const maxAlertHistory = 100
+
+// alertRecord is a single entry in the alert history
+type alertRecord struct {
+	Time     time.Time
+	Message  string
+	Severity int
+	Acked    bool
+}
+
+// severityName maps an alert severity level to its display label
+func severityName(severity int) string {
+	switch severity {
+	case 1:
+		return "WARNING"
+	case 2:
+		return "CAUTION"
+	case 3:
+		return "CRITICAL"
+	}
+	return ""
+}
+
+// raiseAlert activates the HUD alert and records it in the history
+func (m *model) raiseAlert(message string, severity int) {
+	m.alertActive = true
+	m.alertMessage = message
+	m.alertSeverity = severity
+
+	m.alertHistory = append(m.alertHistory, alertRecord{
+		Time:     m.now(),
+		Message:  message,
+		Severity: severity,
+	})
+	m.exporter.alertRaised(severity)
+	if len(m.alertHistory) > maxAlertHistory {
+		m.alertHistory = m.alertHistory[1:]
+	}
+	m.refreshAlertTable()
+}
+
+// acknowledgeAlert dismisses the active alert and marks it acknowledged in the history
+func (m *model) acknowledgeAlert() {
+	if !m.alertActive {
+		return
+	}
+	m.alertActive = false
+	if n := len(m.alertHistory); n > 0 {
+		m.alertHistory[n-1].Acked = true
+	}
+	m.addLog("Alert acknowledged: " + m.alertMessage)
+	m.refreshAlertTable()
+}
+
+// acknowledgeAlertAt acknowledges the i-th newest alert in the history
+func (m *model) acknowledgeAlertAt(i int) {
+	idx := len(m.alertHistory) - 1 - i
+	if idx < 0 || idx >= len(m.alertHistory) {
+		return
+	}
+	if idx == len(m.alertHistory)-1 && m.alertActive {
+		m.acknowledgeAlert()
+		return
+	}
+	if !m.alertHistory[idx].Acked {
+		m.alertHistory[idx].Acked = true
+		m.addLog("Alert acknowledged: " + m.alertHistory[idx].Message)
+		m.refreshAlertTable()
+	}
+}
+
+func (m model) renderAlertHistory() string {
+	theme := m.getTheme()
+
+	boxStyle := lipgloss.NewStyle().
+		Border(lipgloss.DoubleBorder()).
+		BorderForeground(theme.Alert).
+		Padding(1, 2).
+		Background(theme.Background).
+		Foreground(theme.Primary)
+
+	titleStyle := lipgloss.NewStyle().Foreground(theme.Accent).Bold(true)
+	timeStyle := lipgloss.NewStyle().Foreground(theme.Dim)
+	msgStyle := lipgloss.NewStyle().Foreground(theme.Primary)
+
+	lines := []string{titleStyle.Render("ALERT HISTORY"), ""}
+
+	if len(m.alertHistory) == 0 {
+		lines = append(lines, timeStyle.Render("No alerts recorded"))
+	}
+
+	// Newest first, limited to what fits on screen
+	limit := m.height - 10
+	if limit < 1 {
+		limit = 1
+	}
+	for i := len(m.alertHistory) - 1; i >= 0 && len(m.alertHistory)-i <= limit; i-- {
+		a := m.alertHistory[i]
+		severity := lipgloss.NewStyle().Foreground(theme.Alert).Bold(true).
+			Render(fmt.Sprintf("%-8s", severityName(a.Severity)))
+		line := timeStyle.Render(a.Time.Format("15:04:05")) + " " + severity + " " + msgStyle.Render(a.Message)
+		if a.Acked {
+			line += " " + timeStyle.Render("[ACK]")
+		}
+		lines = append(lines, line)
+	}
+
+	lines = append(lines, "", timeStyle.Render(strings.Repeat("─", 36)), timeStyle.Render("a / Esc to close"))
+
+	return boxStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
@@ -0,0 +1,295 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// The snapshot exporters turn a rendered frame back into styled cells so it
+// can be written as HTML or SVG. Only the SGR sequences lipgloss emits are
+// understood; anything else is dropped.
+
+// cellStyle is the SGR state attached to a run of text
+type cellStyle struct {
+	fg, bg    string // "#rrggbb" or empty for the default
+	bold      bool
+	faint     bool
+	italic    bool
+	underline bool
+	reverse   bool
+}
+
+// styledRun is a span of text sharing one style, starting at column col
+type styledRun struct {
+	col   int
+	text  string
+	width int
+	style cellStyle
+}
+
+// ansi16 is the xterm palette for the 16 basic colors
+var ansi16 = [16]string{
+	"#000000", "#cd0000", "#00cd00", "#cdcd00", "#0000ee", "#cd00cd", "#00cdcd", "#e5e5e5",
+	"#7f7f7f", "#ff0000", "#00ff00", "#ffff00", "#5c5cff", "#ff00ff", "#00ffff", "#ffffff",
+}
+
+// ansi256 resolves an xterm 256-color index to a hex color
+func ansi256(n int) string {
+	switch {
+	case n < 16:
+		return ansi16[n]
+	case n < 232:
+		n -= 16
+		levels := []int{0, 95, 135, 175, 215, 255}
+		return fmt.Sprintf("#%02x%02x%02x", levels[n/36], levels[(n/6)%6], levels[n%6])
+	default:
+		g := 8 + (n-232)*10
+		return fmt.Sprintf("#%02x%02x%02x", g, g, g)
+	}
+}
+
+// applySGR updates st from the parameters of one ESC[...m sequence
+func (st *cellStyle) applySGR(params string) {
+	if params == "" {
+		*st = cellStyle{}
+		return
+	}
+
+	codes := strings.Split(params, ";")
+	for i := 0; i < len(codes); i++ {
+		n, _ := strconv.Atoi(codes[i])
+		switch {
+		case n == 0:
+			*st = cellStyle{}
+		case n == 1:
+			st.bold = true
+		case n == 2:
+			st.faint = true
+		case n == 3:
+			st.italic = true
+		case n == 4:
+			st.underline = true
+		case n == 7:
+			st.reverse = true
+		case n == 22:
+			st.bold, st.faint = false, false
+		case n == 23:
+			st.italic = false
+		case n == 24:
+			st.underline = false
+		case n == 27:
+			st.reverse = false
+		case n >= 30 && n <= 37:
+			st.fg = ansi16[n-30]
+		case n >= 90 && n <= 97:
+			st.fg = ansi16[n-90+8]
+		case n == 39:
+			st.fg = ""
+		case n >= 40 && n <= 47:
+			st.bg = ansi16[n-40]
+		case n >= 100 && n <= 107:
+			st.bg = ansi16[n-100+8]
+		case n == 49:
+			st.bg = ""
+		case n == 38 || n == 48:
+			color, used := parseExtendedColor(codes[i+1:])
+			i += used
+			if n == 38 {
+				st.fg = color
+			} else {
+				st.bg = color
+			}
+		}
+	}
+}
+
+// parseExtendedColor reads the arguments of a 38/48 sequence (5;n or 2;r;g;b)
+func parseExtendedColor(args []string) (string, int) {
+	if len(args) == 0 {
+		return "", 0
+	}
+	switch args[0] {
+	case "5":
+		if len(args) < 2 {
+			return "", len(args)
+		}
+		n, _ := strconv.Atoi(args[1])
+		return ansi256(n), 2
+	case "2":
+		if len(args) < 4 {
+			return "", len(args)
+		}
+		r, _ := strconv.Atoi(args[1])
+		g, _ := strconv.Atoi(args[2])
+		b, _ := strconv.Atoi(args[3])
+		return fmt.Sprintf("#%02x%02x%02x", r, g, b), 4
+	}
+	return "", 1
+}
+
+// parseANSI splits a rendered frame into lines of styled runs
+func parseANSI(frame string) [][]styledRun {
+	var lines [][]styledRun
+	var st cellStyle
+
+	for _, line := range strings.Split(frame, "\n") {
+		var runs []styledRun
+		var text strings.Builder
+		col, start, width := 0, 0, 0
+
+		flush := func() {
+			if text.Len() > 0 {
+				runs = append(runs, styledRun{col: start, text: text.String(), width: width, style: st})
+				text.Reset()
+			}
+			start, width = col, 0
+		}
+
+		for i := 0; i < len(line); {
+			if line[i] == 0x1b && i+1 < len(line) && line[i+1] == '[' {
+				// CSI sequence: parameters up to the final byte
+				j := i + 2
+				for j < len(line) && (line[j] < 0x40 || line[j] > 0x7e) {
+					j++
+				}
+				if j < len(line) && line[j] == 'm' {
+					flush()
+					st.applySGR(line[i+2 : j])
+				}
+				i = j + 1
+				continue
+			}
+
+			r, size := utf8.DecodeRuneInString(line[i:])
+			w := runewidth.RuneWidth(r)
+			text.WriteRune(r)
+			col += w
+			width += w
+			i += size
+		}
+		flush()
+		lines = append(lines, runs)
+	}
+	return lines
+}
+
+// colors resolves the effective foreground and background, honoring reverse video
+func (st cellStyle) colors(defaultFg, defaultBg string) (string, string) {
+	fg, bg := st.fg, st.bg
+	if fg == "" {
+		fg = defaultFg
+	}
+	if bg == "" {
+		bg = defaultBg
+	}
+	if st.reverse {
+		fg, bg = bg, fg
+	}
+	return fg, bg
+}
+
+const (
+	exportFg = "#c0c0c0"
+	exportBg = "#0a0a0a"
+)
+
+// writeHTML renders a frame as a standalone HTML page
+func writeHTML(w io.Writer, frame string) error {
+	var sb strings.Builder
+	sb.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>J.A.R.V.I.S. Snapshot</title>\n")
+	fmt.Fprintf(&sb, "<style>body{background:%s;margin:0}pre{color:%s;font-family:'JetBrains Mono','Fira Code',monospace;font-size:13px;line-height:1.2;margin:16px}</style>\n", exportBg, exportFg)
+	sb.WriteString("</head>\n<body>\n<pre>")
+
+	for i, runs := range parseANSI(frame) {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		for _, r := range runs {
+			text := html.EscapeString(r.text)
+			if r.style == (cellStyle{}) {
+				sb.WriteString(text)
+				continue
+			}
+			fg, bg := r.style.colors(exportFg, exportBg)
+			css := "color:" + fg
+			if bg != exportBg {
+				css += ";background:" + bg
+			}
+			if r.style.bold {
+				css += ";font-weight:bold"
+			}
+			if r.style.faint {
+				css += ";opacity:0.6"
+			}
+			if r.style.italic {
+				css += ";font-style:italic"
+			}
+			if r.style.underline {
+				css += ";text-decoration:underline"
+			}
+			fmt.Fprintf(&sb, "<span style=\"%s\">%s</span>", css, text)
+		}
+	}
+
+	sb.WriteString("</pre>\n</body>\n</html>\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// writeSVG renders a frame as an SVG image on a fixed character grid
+func writeSVG(w io.Writer, frame string, cols, rows int) error {
+	const (
+		cellW  = 8.4
+		cellH  = 17.0
+		margin = 16.0
+	)
+
+	var sb strings.Builder
+	width := float64(cols)*cellW + 2*margin
+	height := float64(rows)*cellH + 2*margin
+	fmt.Fprintf(&sb, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%.0f\" height=\"%.0f\" viewBox=\"0 0 %.0f %.0f\">\n", width, height, width, height)
+	sb.WriteString("<style>text{font-family:'JetBrains Mono','Fira Code',monospace;font-size:14px;white-space:pre}</style>\n")
+	fmt.Fprintf(&sb, "<rect width=\"100%%\" height=\"100%%\" fill=\"%s\"/>\n", exportBg)
+
+	for row, runs := range parseANSI(frame) {
+		y := margin + float64(row)*cellH
+		for _, r := range runs {
+			x := margin + float64(r.col)*cellW
+			fg, bg := r.style.colors(exportFg, exportBg)
+			if bg != exportBg {
+				fmt.Fprintf(&sb, "<rect x=\"%.1f\" y=\"%.1f\" width=\"%.1f\" height=\"%.1f\" fill=\"%s\"/>\n",
+					x, y, float64(r.width)*cellW, cellH, bg)
+			}
+			if strings.TrimSpace(r.text) == "" {
+				continue
+			}
+
+			attrs := fmt.Sprintf("fill=\"%s\"", fg)
+			if r.style.bold {
+				attrs += " font-weight=\"bold\""
+			}
+			if r.style.faint {
+				attrs += " fill-opacity=\"0.6\""
+			}
+			if r.style.italic {
+				attrs += " font-style=\"italic\""
+			}
+			if r.style.underline {
+				attrs += " text-decoration=\"underline\""
+			}
+			// Pin the run to its grid width so wide glyphs don't drift
+			fmt.Fprintf(&sb, "<text x=\"%.1f\" y=\"%.1f\" textLength=\"%.1f\" lengthAdjust=\"spacingAndGlyphs\" %s>%s</text>\n",
+				x, y+cellH*0.8, float64(r.width)*cellW, attrs, html.EscapeString(r.text))
+		}
+	}
+
+	sb.WriteString("</svg>\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
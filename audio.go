@@ -0,0 +1,316 @@
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/cmplx"
+	"os"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// AudioConfig feeds the AUDIO ANALYSIS bars from raw PCM, e.g.
+// `parec --format=s16le --channels=2 --rate=44100 | jarvis --audio -`
+type AudioConfig struct {
+	Input      string `json:"input"`       // file or FIFO path, or "-" for stdin
+	Format     string `json:"format"`      // "s16le" (default) or "f32le"
+	SampleRate int    `json:"sample_rate"` // default 44100
+	Channels   int    `json:"channels"`    // default 2, mixed down to mono
+}
+
+const (
+	// fftSize is the analysis window: 46ms at 44.1kHz
+	fftSize = 2048
+	// audioChunk is how many frames the reader takes at a time
+	audioChunk = 512
+
+	// The bars span bandLow to bandHigh Hz on a log scale
+	bandLow  = 40.0
+	bandHigh = 16000.0
+	// floorDB is the level drawn as an empty bar, relative to full scale
+	floorDB = -60.0
+
+	// Smoothing: bars jump up quickly and fall back slowly
+	audioAttack  = 0.6
+	audioRelease = 0.25
+	// Peak markers hold for peakHoldTicks readings, then fall by peakFall
+	peakHoldTicks = 5
+	peakFall      = 0.05
+)
+
+type audioMsg struct {
+	levels []float64 // one per band, 0-1; nil until a full window arrives
+	err    error
+}
+
+// audioSource reads PCM in the background and keeps the latest window
+type audioSource struct {
+	input      string
+	sampleRate int
+	channels   int
+	sampleSize int // bytes
+	float      bool
+
+	mu     sync.Mutex
+	window []float64
+	err    error
+	file   *os.File
+	closed bool
+}
+
+func newAudioSource(cfg *AudioConfig) (*audioSource, error) {
+	if cfg == nil || cfg.Input == "" {
+		return nil, nil
+	}
+	s := &audioSource{input: cfg.Input, sampleRate: 44100, channels: 2, sampleSize: 2}
+	if cfg.SampleRate > 0 {
+		s.sampleRate = cfg.SampleRate
+	}
+	if cfg.Channels > 0 {
+		s.channels = cfg.Channels
+	}
+	switch cfg.Format {
+	case "", "s16le":
+	case "f32le":
+		s.sampleSize, s.float = 4, true
+	default:
+		return nil, fmt.Errorf("audio: unknown format %q (want \"s16le\" or \"f32le\")", cfg.Format)
+	}
+	return s, nil
+}
+
+// start opens the input and reads it until it ends. Opening a FIFO blocks
+// until a writer connects, so that happens in the background too.
+func (s *audioSource) start() {
+	if s == nil {
+		return
+	}
+	go s.run()
+}
+
+func (s *audioSource) close() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	if s.file != nil && s.file != os.Stdin {
+		s.file.Close()
+	}
+}
+
+func (s *audioSource) run() {
+	f := os.Stdin
+	if s.input != "-" {
+		var err error
+		if f, err = os.Open(s.input); err != nil {
+			s.fail(err)
+			return
+		}
+	}
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		f.Close()
+		return
+	}
+	s.file = f
+	s.mu.Unlock()
+
+	// A regular file is played in real time and loops; pipes set their own pace
+	frame := s.sampleSize * s.channels
+	regular := false
+	if fi, err := f.Stat(); err == nil && fi.Mode().IsRegular() {
+		if fi.Size() < int64(frame) {
+			s.fail(errors.New("input is empty"))
+			return
+		}
+		regular = true
+	}
+	chunk := time.Duration(audioChunk) * time.Second / time.Duration(s.sampleRate)
+
+	buf := make([]byte, audioChunk*frame)
+	for {
+		n, err := io.ReadFull(f, buf)
+		s.push(buf[:n-n%frame])
+		switch {
+		case err == io.EOF || err == io.ErrUnexpectedEOF:
+			if !regular {
+				s.fail(errors.New("input ended"))
+				return
+			}
+			if _, err := f.Seek(0, io.SeekStart); err != nil {
+				s.fail(err)
+				return
+			}
+		case err != nil:
+			s.fail(err)
+			return
+		}
+		if regular {
+			time.Sleep(chunk)
+		}
+	}
+}
+
+func (s *audioSource) fail(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.closed {
+		s.err = err
+	}
+}
+
+// push mixes frames down to mono and appends them to the window
+func (s *audioSource) push(b []byte) {
+	frame := s.sampleSize * s.channels
+	samples := make([]float64, 0, len(b)/frame)
+	for off := 0; off+frame <= len(b); off += frame {
+		sum := 0.0
+		for c := 0; c < s.channels; c++ {
+			sum += s.decode(b[off+c*s.sampleSize:])
+		}
+		samples = append(samples, sum/float64(s.channels))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.window = append(s.window, samples...)
+	if extra := len(s.window) - fftSize; extra > 0 {
+		s.window = append(s.window[:0], s.window[extra:]...)
+	}
+}
+
+func (s *audioSource) decode(b []byte) float64 {
+	if s.float {
+		return float64(math.Float32frombits(binary.LittleEndian.Uint32(b)))
+	}
+	return float64(int16(binary.LittleEndian.Uint16(b))) / 32768
+}
+
+// read analyzes the latest window
+func (s *audioSource) read() audioMsg {
+	s.mu.Lock()
+	window := append([]float64(nil), s.window...)
+	err := s.err
+	s.mu.Unlock()
+
+	if len(window) < fftSize {
+		return audioMsg{err: err}
+	}
+	return audioMsg{levels: spectrumBands(window, s.sampleRate, audioBands), err: err}
+}
+
+func readAudioCommand(s *audioSource) tea.Cmd {
+	return func() tea.Msg {
+		return s.read()
+	}
+}
+
+// spectrumBands runs a Hann-windowed FFT over samples and sums it into n
+// log-spaced bands between bandLow and bandHigh. Each band is its loudest
+// bin in dB, mapped from floorDB..0 dBFS onto 0..1.
+func spectrumBands(samples []float64, sampleRate, n int) []float64 {
+	size := len(samples)
+	buf := make([]complex128, size)
+	for i, v := range samples {
+		hann := 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(size-1))
+		buf[i] = complex(v*hann, 0)
+	}
+	fft(buf)
+
+	// A full-scale sine peaks at size/4 after the Hann window
+	ref := float64(size) / 4
+	binHz := float64(sampleRate) / float64(size)
+	high := min(bandHigh, float64(sampleRate)/2)
+
+	levels := make([]float64, n)
+	for b := range levels {
+		lo := bandLow * math.Pow(high/bandLow, float64(b)/float64(n))
+		hi := bandLow * math.Pow(high/bandLow, float64(b+1)/float64(n))
+		first, last := int(math.Ceil(lo/binHz)), int(math.Ceil(hi/binHz))-1
+		if last < first {
+			// Narrow low bands may fall between bins; use the nearest
+			first = int(math.Round(math.Sqrt(lo*hi) / binHz))
+			last = first
+		}
+		peak := 0.0
+		for k := first; k <= last && k < size/2; k++ {
+			peak = max(peak, cmplx.Abs(buf[k]))
+		}
+		if peak == 0 {
+			continue
+		}
+		db := 20 * math.Log10(peak/ref)
+		levels[b] = min(max((db-floorDB)/-floorDB, 0), 1)
+	}
+	return levels
+}
+
+// fft is an in-place radix-2 Cooley-Tukey transform; len(x) must be a
+// power of two
+func fft(x []complex128) {
+	n := len(x)
+	for i, j := 1, 0; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j |= bit
+		if i < j {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+	for size := 2; size <= n; size <<= 1 {
+		step := cmplx.Rect(1, -2*math.Pi/float64(size))
+		for start := 0; start < n; start += size {
+			w := complex(1, 0)
+			for k := 0; k < size/2; k++ {
+				a, b := x[start+k], w*x[start+k+size/2]
+				x[start+k], x[start+k+size/2] = a+b, a-b
+				w *= step
+			}
+		}
+	}
+}
+
+// --- Model glue ---
+
+// audioBands is the number of bars in the visualizer
+const audioBands = 16
+
+// setAudio eases the bars toward a new reading and moves the peak markers
+func (m *model) setAudio(msg audioMsg) {
+	if msg.err != nil && m.audioErr == nil {
+		m.addLog(fmt.Sprintf("Audio input: %v", msg.err))
+	}
+	m.audioErr = msg.err
+	m.audioLive = true
+
+	for i := range m.audioLevels {
+		target := 0.0
+		if i < len(msg.levels) {
+			target = msg.levels[i]
+		}
+		rate := audioRelease
+		if target > m.audioLevels[i] {
+			rate = audioAttack
+		}
+		m.audioLevels[i] += (target - m.audioLevels[i]) * rate
+
+		switch {
+		case m.audioLevels[i] >= m.audioPeaks[i]:
+			m.audioPeaks[i], m.peakHold[i] = m.audioLevels[i], peakHoldTicks
+		case m.peakHold[i] > 0:
+			m.peakHold[i]--
+		default:
+			m.audioPeaks[i] = max(m.audioPeaks[i]-peakFall, m.audioLevels[i])
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"encoding/binary"
+	"math"
+	"math/cmplx"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// sine returns n samples of a tone at freq Hz with the given amplitude
+func sine(freq, amp float64, rate, n int) []float64 {
+	s := make([]float64, n)
+	for i := range s {
+		s[i] = amp * math.Sin(2*math.Pi*freq*float64(i)/float64(rate))
+	}
+	return s
+}
+
+// loudest is the index of the largest level
+func loudest(levels []float64) int {
+	best := 0
+	for i, v := range levels {
+		if v > levels[best] {
+			best = i
+		}
+	}
+	return best
+}
+
+func TestFFT(t *testing.T) {
+	x := make([]complex128, 64)
+	for i := range x {
+		x[i] = complex(math.Cos(2*math.Pi*5*float64(i)/64), 0)
+	}
+	fft(x)
+	for k, v := range x[:32] {
+		want := 0.0
+		if k == 5 {
+			want = 32
+		}
+		if math.Abs(cmplx.Abs(v)-want) > 1e-9 {
+			t.Errorf("bin %d = %v, want %v", k, cmplx.Abs(v), want)
+		}
+	}
+}
+
+func TestSpectrumBands(t *testing.T) {
+	bass := spectrumBands(sine(60, 1, 44100, fftSize), 44100, audioBands)
+	treble := spectrumBands(sine(8000, 1, 44100, fftSize), 44100, audioBands)
+	if b := loudest(bass); b > 1 {
+		t.Errorf("60 Hz lands in band %d: %v", b, bass)
+	}
+	if b := loudest(treble); b < audioBands-3 {
+		t.Errorf("8 kHz lands in band %d: %v", b, treble)
+	}
+	// A full-scale tone reaches the top of its bar; -30 dB is about half way
+	if top := treble[loudest(treble)]; top < 0.95 {
+		t.Errorf("full-scale level = %v", top)
+	}
+	quiet := spectrumBands(sine(8000, math.Pow(10, -30.0/20), 44100, fftSize), 44100, audioBands)
+	if l := quiet[loudest(quiet)]; l < 0.45 || l > 0.55 {
+		t.Errorf("-30 dBFS level = %v, want about 0.5", l)
+	}
+	if silent := spectrumBands(make([]float64, fftSize), 44100, audioBands); silent[loudest(silent)] != 0 {
+		t.Errorf("silence = %v", silent)
+	}
+}
+
+func TestAudioSourceReadsPCM(t *testing.T) {
+	// Stereo s16le: a 2 kHz tone on the left channel only
+	tone := sine(2000, 0.8, 44100, 4*fftSize)
+	pcm := make([]byte, 0, len(tone)*4)
+	for _, v := range tone {
+		pcm = binary.LittleEndian.AppendUint16(pcm, uint16(int16(v*32767)))
+		pcm = binary.LittleEndian.AppendUint16(pcm, 0)
+	}
+	path := filepath.Join(t.TempDir(), "tone.pcm")
+	if err := os.WriteFile(path, pcm, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	src, err := newAudioSource(&AudioConfig{Input: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	src.start()
+	defer src.close()
+
+	var msg audioMsg
+	for deadline := time.Now().Add(5 * time.Second); msg.levels == nil; time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("no audio window after 5s")
+		}
+		msg = src.read()
+	}
+	if msg.err != nil {
+		t.Fatal(msg.err)
+	}
+	want := spectrumBands(sine(2000, 0.4, 44100, fftSize), 44100, audioBands)
+	if got := loudest(msg.levels); got != loudest(want) {
+		t.Errorf("loudest band = %d, want %d: %v", got, loudest(want), msg.levels)
+	}
+
+	if _, err := newAudioSource(&AudioConfig{Input: "-", Format: "u8"}); err == nil {
+		t.Error("unknown format accepted")
+	}
+	missing, _ := newAudioSource(&AudioConfig{Input: filepath.Join(t.TempDir(), "missing")})
+	missing.run()
+	if msg := missing.read(); msg.err == nil {
+		t.Error("missing input reports no error")
+	}
+}
+
+func TestAudioSmoothingAndPeakHold(t *testing.T) {
+	m := newTestModel(t, 120, 40)
+	level := func(v float64) audioMsg {
+		levels := make([]float64, audioBands)
+		levels[0] = v
+		return audioMsg{levels: levels}
+	}
+
+	m = step(m, level(1))
+	if got := m.audioLevels[0]; got != audioAttack {
+		t.Errorf("attack: %v, want %v", got, audioAttack)
+	}
+	if m.audioLevels[1] != 0 {
+		t.Errorf("RNG still drives the bars: %v", m.audioLevels)
+	}
+	m = step(m, level(1))
+	peak := m.audioLevels[0]
+
+	// The bar falls slowly; the peak holds, then falls
+	m = step(m, level(0))
+	if got := m.audioLevels[0]; got != peak*(1-audioRelease) {
+		t.Errorf("release: %v, want %v", got, peak*(1-audioRelease))
+	}
+	for i := 1; i < peakHoldTicks; i++ {
+		m = step(m, level(0))
+	}
+	if m.audioPeaks[0] != peak {
+		t.Errorf("peak fell during hold: %v, want %v", m.audioPeaks[0], peak)
+	}
+	m = step(m, level(0))
+	m = step(m, level(0))
+	if m.audioPeaks[0] >= peak {
+		t.Errorf("peak did not fall after hold: %v", m.audioPeaks[0])
+	}
+	if !strings.Contains(m.renderSoundWave(), "▔") {
+		t.Error("peak marker not drawn above a fallen bar")
+	}
+
+	// Ticks leave live levels alone
+	before := m.audioLevels[0]
+	m = step(m, tickMsg(testTime))
+	if m.audioLevels[0] != before {
+		t.Error("tick overwrote live audio levels")
+	}
+}
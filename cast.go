@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/term"
+)
+
+// castRecorder tees the program's terminal output into an asciicast v2
+// file (https://docs.asciinema.org/manual/asciicast/v2/). It implements
+// term.File by delegating to the real terminal, so Bubble Tea still
+// detects the TTY, sizes the window and sets raw mode as usual.
+type castRecorder struct {
+	term.File // the terminal being wrapped
+
+	mu      sync.Mutex
+	w       *bufio.Writer
+	start   time.Time
+	now     func() time.Time
+	pending []byte // an incomplete UTF-8 sequence held for the next write
+	err     error
+}
+
+type castHeader struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+func newCastRecorder(out term.File, cast io.Writer, width, height int, now func() time.Time) (*castRecorder, error) {
+	c := &castRecorder{File: out, w: bufio.NewWriter(cast), start: now(), now: now}
+	hdr := castHeader{
+		Version:   2,
+		Width:     width,
+		Height:    height,
+		Timestamp: c.start.Unix(),
+		Env:       map[string]string{"TERM": os.Getenv("TERM"), "SHELL": os.Getenv("SHELL")},
+	}
+	if err := json.NewEncoder(c.w).Encode(hdr); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Write sends p to the terminal and appends it to the cast as an output event
+func (c *castRecorder) Write(p []byte) (int, error) {
+	n, err := c.File.Write(p)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data := append(c.pending, p[:n]...)
+	// Bubble Tea writes whole frames, but never split a rune across events
+	cut := len(data)
+	for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(data[i]) {
+			if !utf8.FullRune(data[i:]) {
+				cut = i
+			}
+			break
+		}
+	}
+	c.pending = append([]byte(nil), data[cut:]...)
+	if cut > 0 {
+		c.event("o", string(data[:cut]))
+	}
+	return n, err
+}
+
+// resize records a terminal size change
+func (c *castRecorder) resize(width, height int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.event("r", fmt.Sprintf("%dx%d", width, height))
+}
+
+// event appends one [time, code, data] line; callers hold mu
+func (c *castRecorder) event(code, data string) {
+	if c.err != nil {
+		return
+	}
+	elapsed := c.now().Sub(c.start).Seconds()
+	line, err := json.Marshal([]any{json.Number(fmt.Sprintf("%.6f", elapsed)), code, data})
+	if err != nil {
+		c.err = err
+		return
+	}
+	line = append(line, '\n')
+	_, c.err = c.w.Write(line)
+}
+
+// filter is a tea.WithFilter hook that records window resizes
+func (c *castRecorder) filter(_ tea.Model, msg tea.Msg) tea.Msg {
+	if ws, ok := msg.(tea.WindowSizeMsg); ok {
+		c.resize(ws.Width, ws.Height)
+	}
+	return msg
+}
+
+// Close flushes the cast; the wrapped terminal stays open
+func (c *castRecorder) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.pending) > 0 {
+		c.event("o", string(c.pending))
+		c.pending = nil
+	}
+	if err := c.w.Flush(); c.err == nil {
+		c.err = err
+	}
+	return c.err
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// fakeTerminal stands in for the TTY the recorder wraps
+type fakeTerminal struct {
+	bytes.Buffer
+}
+
+func (*fakeTerminal) Close() error { return nil }
+func (*fakeTerminal) Fd() uintptr  { return 0 }
+
+func TestCastRecorder(t *testing.T) {
+	clock := testTime
+	now := func() time.Time { return clock }
+
+	var tty fakeTerminal
+	var cast bytes.Buffer
+	c, err := newCastRecorder(&tty, &cast, 120, 40, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clock = clock.Add(250 * time.Millisecond)
+	c.Write([]byte("\x1b[1mJARVIS\x1b[0m"))
+
+	// A rune split across two writes lands in a single event
+	arrow := []byte("▶")
+	clock = clock.Add(time.Second)
+	c.Write(arrow[:1])
+	clock = clock.Add(time.Second)
+	c.Write(arrow[1:])
+
+	c.filter(nil, tea.WindowSizeMsg{Width: 100, Height: 30})
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := tty.String(), "\x1b[1mJARVIS\x1b[0m▶"; got != want {
+		t.Errorf("terminal got %q, want %q", got, want)
+	}
+
+	sc := bufio.NewScanner(&cast)
+	sc.Scan()
+	var hdr castHeader
+	if err := json.Unmarshal(sc.Bytes(), &hdr); err != nil {
+		t.Fatal(err)
+	}
+	if hdr.Version != 2 || hdr.Width != 120 || hdr.Height != 40 || hdr.Timestamp != testTime.Unix() {
+		t.Errorf("unexpected header %+v", hdr)
+	}
+
+	want := []struct {
+		t    float64
+		code string
+		data string
+	}{
+		{0.25, "o", "\x1b[1mJARVIS\x1b[0m"},
+		{2.25, "o", "▶"},
+		{2.25, "r", "100x30"},
+	}
+	for i, w := range want {
+		if !sc.Scan() {
+			t.Fatalf("missing event %d", i)
+		}
+		var ev []any
+		if err := json.Unmarshal(sc.Bytes(), &ev); err != nil {
+			t.Fatalf("event %d: %v", i, err)
+		}
+		if len(ev) != 3 || ev[0] != w.t || ev[1] != w.code || ev[2] != w.data {
+			t.Errorf("event %d = %v, want [%v %q %q]", i, ev, w.t, w.code, w.data)
+		}
+	}
+	if sc.Scan() {
+		t.Errorf("unexpected extra event %s", sc.Text())
+	}
+}
@@ -0,0 +1,275 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/shirou/gopsutil/v3/disk"
+	"github.com/shirou/gopsutil/v3/net"
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// Background collectors run as commands so slow syscalls never block the
+// render loop. They are scheduled from the tick regardless of which page is
+// showing, so hidden pages are current when you switch to them.
+
+// maxProcesses caps how many processes the table keeps
+const maxProcesses = 200
+
+type processInfo struct {
+	PID  int32
+	Name string
+	CPU  float64
+	Mem  float32
+	RSS  uint64
+}
+
+type diskInfo struct {
+	Mount       string
+	FSType      string
+	Total       uint64
+	Used        uint64
+	Free        uint64
+	UsedPercent float64
+}
+
+type processesMsg struct {
+	procs []processInfo
+	err   error
+}
+
+type disksMsg []diskInfo
+
+type ifacesMsg struct {
+	at    time.Time
+	stats []net.IOCountersStat
+}
+
+// processCache keeps Process handles alive between samples so CPU usage
+// is measured over the collection interval rather than since process start
+type processCache struct {
+	mu    sync.Mutex
+	procs map[int32]*process.Process
+}
+
+func newProcessCache() *processCache {
+	return &processCache{procs: make(map[int32]*process.Process)}
+}
+
+func (c *processCache) collect() processesMsg {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	procs, err := process.Processes()
+	if err != nil {
+		return processesMsg{err: err}
+	}
+
+	seen := make(map[int32]*process.Process, len(procs))
+	infos := make([]processInfo, 0, len(procs))
+	for _, p := range procs {
+		if cached, ok := c.procs[p.Pid]; ok {
+			p = cached
+		}
+		seen[p.Pid] = p
+
+		name, err := p.Name()
+		if err != nil {
+			continue // Process exited mid-scan
+		}
+		info := processInfo{PID: p.Pid, Name: name}
+		info.CPU, _ = p.Percent(0)
+		info.Mem, _ = p.MemoryPercent()
+		if mi, err := p.MemoryInfo(); err == nil {
+			info.RSS = mi.RSS
+		}
+		infos = append(infos, info)
+	}
+	c.procs = seen
+	return processesMsg{procs: topProcesses(infos)}
+}
+
+// topProcesses orders processes busiest first and keeps the first
+// maxProcesses
+func topProcesses(infos []processInfo) []processInfo {
+	sort.Slice(infos, func(i, j int) bool {
+		if infos[i].CPU != infos[j].CPU {
+			return infos[i].CPU > infos[j].CPU
+		}
+		return infos[i].Mem > infos[j].Mem
+	})
+	if len(infos) > maxProcesses {
+		infos = infos[:maxProcesses]
+	}
+	return infos
+}
+
+func collectProcessesCommand(cache *processCache) tea.Cmd {
+	return func() tea.Msg {
+		return cache.collect()
+	}
+}
+
+func collectDisksCommand() tea.Cmd {
+	return func() tea.Msg {
+		return collectDisks()
+	}
+}
+
+func collectDisks() disksMsg {
+	parts, err := disk.Partitions(false)
+	if err != nil {
+		return nil
+	}
+	var disks disksMsg
+	for _, p := range parts {
+		usage, err := disk.Usage(p.Mountpoint)
+		if err != nil || usage.Total == 0 {
+			continue
+		}
+		disks = append(disks, diskInfo{
+			Mount:       p.Mountpoint,
+			FSType:      p.Fstype,
+			Total:       usage.Total,
+			Used:        usage.Used,
+			Free:        usage.Free,
+			UsedPercent: usage.UsedPercent,
+		})
+	}
+	return disks
+}
+
+func collectIfacesCommand() tea.Cmd {
+	return func() tea.Msg {
+		return collectIfaces()
+	}
+}
+
+func collectIfaces() ifacesMsg {
+	stats, err := net.IOCounters(true)
+	if err != nil {
+		return ifacesMsg{at: time.Now()}
+	}
+	return ifacesMsg{at: time.Now(), stats: stats}
+}
+
+// backgroundCollectCommands returns every collector for one round. While
+// drilled into a fleet host the dashboard reads that agent instead, and a
+// source standing in for another machine turns off the collectors that can
+// only read this one.
+func (m model) backgroundCollectCommands() []tea.Cmd {
+	var cmds []tea.Cmd
+	if m.fleet != nil {
+		cmds = append(cmds, collectFleetCommand(m.fleet))
+	}
+	if m.remote != nil {
+		return append(cmds, m.remote.collectCommands()...)
+	}
+	cmds = append(cmds,
+		collectSampleCommand(m.source),
+		m.processesCommand(),
+		m.disksCommand(),
+		m.ifacesCommand(),
+	)
+	if !m.localHost() {
+		return cmds
+	}
+	if m.docker != nil {
+		cmds = append(cmds, collectContainersCommand(m.docker))
+	}
+	if m.thermal != nil {
+		cmds = append(cmds, collectThermalCommand(m.thermal))
+	}
+	if m.supplies != nil {
+		cmds = append(cmds, collectPowerCommand(m.supplies))
+	}
+	if m.memReader != nil {
+		cmds = append(cmds, collectMemoryCommand(m.memReader))
+	}
+	if m.connReader != nil {
+		cmds = append(cmds, collectConnectionsCommand(m.connReader))
+	}
+	if m.resBinding.diskIO() {
+		cmds = append(cmds, collectDiskIOCommand())
+	}
+	return cmds
+}
+
+// localHost reports whether the pages show this machine rather than a
+// fleet host or a source's host
+func (m model) localHost() bool {
+	_, other := m.source.(HostSource)
+	return m.remote == nil && !other
+}
+
+// localVitals reports whether vitals samples describe this machine.
+// Prometheus vitals aggregate a whole cluster even though the other pages
+// stay local.
+func (m model) localVitals() bool {
+	_, cluster := m.source.(*prometheusSource)
+	return m.localHost() && !cluster
+}
+
+// processesCommand collects the process table from the metric source when
+// it can supply one, and from this machine otherwise
+func (m model) processesCommand() tea.Cmd {
+	src, ok := m.source.(ProcessSource)
+	if !ok {
+		return collectProcessesCommand(m.procCache)
+	}
+	return func() tea.Msg {
+		procs, err := src.Processes()
+		if err != nil {
+			return processesMsg{err: err}
+		}
+		return processesMsg{procs: topProcesses(procs)}
+	}
+}
+
+// disksCommand collects filesystems from the metric source when it can
+// supply them, and from this machine otherwise
+func (m model) disksCommand() tea.Cmd {
+	src, ok := m.source.(DiskSource)
+	if !ok {
+		return collectDisksCommand()
+	}
+	return func() tea.Msg {
+		disks, err := src.Disks()
+		if err != nil {
+			return disksMsg(nil)
+		}
+		return disksMsg(disks)
+	}
+}
+
+// ifacesCommand is disksCommand for network interfaces
+func (m model) ifacesCommand() tea.Cmd {
+	src, ok := m.source.(IfaceSource)
+	if !ok {
+		return collectIfacesCommand()
+	}
+	return func() tea.Msg {
+		stats, err := src.Ifaces()
+		if err != nil {
+			return ifacesMsg{at: time.Now()}
+		}
+		return ifacesMsg{at: time.Now(), stats: stats}
+	}
+}
+
+// formatBytes renders a byte count with a binary unit suffix
+func formatBytes(b uint64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%d B", b)
+	}
+	div, exp := uint64(unit), 0
+	for n := b / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(b)/float64(div), "KMGTPE"[exp])
+}
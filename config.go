@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Config holds user settings loaded from the JSON config file
+type Config struct {
+	// Keys overrides keybindings by action name, e.g. {"theme": ["T"]}
+	Keys map[string][]string `json:"keys"`
+
+	// Source replaces the local vitals collector, e.g. with a Prometheus server
+	Source *SourceConfig `json:"source,omitempty"`
+
+	// Widgets poll JSON endpoints for the Services page
+	Widgets []WidgetConfig `json:"widgets"`
+
+	// Agents are hosts running `jarvis agent`, shown on the Fleet page
+	Agents []AgentConfig `json:"agents"`
+
+	// Docker configures the Containers page
+	Docker *DockerConfig `json:"docker,omitempty"`
+
+	// Thermal moves the sysfs roots the Sensors page reads
+	Thermal *ThermalConfig `json:"thermal,omitempty"`
+
+	// Power configures the power panel and the THRUSTER POWER bar
+	Power *PowerConfig `json:"power,omitempty"`
+
+	// Ports allowlists services that may listen on every interface
+	Ports *PortsConfig `json:"ports,omitempty"`
+
+	// Audio drives the sound visualizer from a PCM stream
+	Audio *AudioConfig `json:"audio,omitempty"`
+
+	// Resonance binds the strip under the arc reactor to a metric
+	Resonance *ResonanceConfig `json:"resonance,omitempty"`
+}
+
+// SourceConfig selects where the vitals panel gets its samples
+type SourceConfig struct {
+	Type string `json:"type"` // "local" (default), "prometheus" or "ssh"
+
+	Prometheus PrometheusConfig `json:"prometheus"`
+	SSH        SSHConfig        `json:"ssh"`
+}
+
+// Duration is a time.Duration read from a string like "5s" or "1m30s"
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("duration must be a string like \"5s\": %w", err)
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// metricSource builds the configured vitals source
+func (c *SourceConfig) metricSource() (MetricSource, error) {
+	if c == nil {
+		return localSource{}, nil
+	}
+	switch c.Type {
+	case "", "local":
+		return localSource{}, nil
+	case "prometheus":
+		return newPrometheusSource(c.Prometheus)
+	case "ssh":
+		return newSSHSource(c.SSH)
+	}
+	return nil, fmt.Errorf("unknown source type %q (want \"local\", \"prometheus\" or \"ssh\")", c.Type)
+}
+
+// defaultConfigPath returns $XDG_CONFIG_HOME/jarvis/config.json (or the OS equivalent)
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "jarvis", "config.json")
+}
+
+// loadConfig reads the config file at path. A missing file is not an error
+// unless required is set, so a fresh install runs with defaults.
+func loadConfig(path string, required bool) (Config, error) {
+	var cfg Config
+	if path == "" {
+		return cfg, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) && !required {
+			return cfg, nil
+		}
+		return cfg, err
+	}
+
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return cfg, nil
+}
@@ -0,0 +1,350 @@
+package main
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	gnet "github.com/shirou/gopsutil/v3/net"
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+const (
+	// maxConnections caps the table and the radar
+	maxConnections = 500
+	// radarMaxAge is the connection age plotted at the radar's rim
+	radarMaxAge = 10 * time.Minute
+	// radarFade is how long a closed connection lingers on the radar
+	radarFade = 10 * time.Second
+	// radarSweepStep is how far the sweep turns per tick, in degrees
+	radarSweepStep = 6.0
+)
+
+// connInfo is one TCP or UDP socket with a remote peer
+type connInfo struct {
+	Proto   string `json:"proto"` // tcp, tcp6, udp, udp6
+	Local   string `json:"local"`
+	Remote  string `json:"remote"`
+	Status  string `json:"status"`
+	PID     int32  `json:"pid"`
+	Process string `json:"process"`
+}
+
+// key identifies a connection across samples
+func (c connInfo) key() string {
+	return c.Proto + " " + c.Local + " " + c.Remote
+}
+
+// remoteHost is the peer address without its port
+func (c connInfo) remoteHost() string {
+	host, _, err := net.SplitHostPort(c.Remote)
+	if err != nil {
+		return c.Remote
+	}
+	return host
+}
+
+type connectionsMsg struct {
+	conns     []connInfo
+	listeners []listenInfo
+	err       error
+}
+
+// connectionReader lists this machine's sockets with their processes
+type connectionReader struct{}
+
+func (r *connectionReader) read() connectionsMsg {
+	stats, err := gnet.Connections("inet")
+	if err != nil {
+		return connectionsMsg{err: err}
+	}
+
+	names := make(map[int32]string)
+	processName := func(pid int32) string {
+		// Sockets of other users' processes come back without a pid
+		if pid <= 0 {
+			return ""
+		}
+		name, ok := names[pid]
+		if !ok {
+			if p, err := process.NewProcess(pid); err == nil {
+				name, _ = p.Name()
+			}
+			names[pid] = name
+		}
+		return name
+	}
+
+	var msg connectionsMsg
+	for _, s := range stats {
+		proto := socketProto(s)
+		if isListener(s) {
+			msg.listeners = append(msg.listeners, listenInfo{
+				Proto:   proto,
+				Addr:    s.Laddr.IP,
+				Port:    s.Laddr.Port,
+				PID:     s.Pid,
+				Process: processName(s.Pid),
+			})
+			continue
+		}
+		// Unconnected sockets have no peer to plot
+		if s.Raddr.IP == "" || s.Raddr.Port == 0 || len(msg.conns) == maxConnections {
+			continue
+		}
+		c := connInfo{
+			Proto:   proto,
+			Local:   net.JoinHostPort(s.Laddr.IP, strconv.Itoa(int(s.Laddr.Port))),
+			Remote:  net.JoinHostPort(s.Raddr.IP, strconv.Itoa(int(s.Raddr.Port))),
+			Status:  s.Status,
+			PID:     s.Pid,
+			Process: processName(s.Pid),
+		}
+		if c.Status == "" || c.Status == "NONE" {
+			c.Status = "-"
+		}
+		msg.conns = append(msg.conns, c)
+	}
+	return msg
+}
+
+func collectConnectionsCommand(r *connectionReader) tea.Cmd {
+	return func() tea.Msg {
+		return r.read()
+	}
+}
+
+func socketProto(s gnet.ConnectionStat) string {
+	proto := "tcp"
+	if s.Type == syscall.SOCK_DGRAM {
+		proto = "udp"
+	}
+	if s.Family == syscall.AF_INET6 {
+		proto += "6"
+	}
+	return proto
+}
+
+// --- Model glue ---
+
+// radarBlip is a plotted peer: bearing from the hash of its address,
+// range from the connection's age
+type radarBlip struct {
+	key      string
+	angle    float64 // degrees
+	dist     float64 // 0 (center) to 1 (rim)
+	closedAt time.Time
+}
+
+// peerBearing spreads peers around the radar. All connections to the same
+// host share a bearing.
+func peerBearing(host string) float64 {
+	h := fnv.New32a()
+	h.Write([]byte(host))
+	return float64(h.Sum32() % 360)
+}
+
+// ageRange maps a connection's age onto the radar, log scaled so new
+// connections spread out instead of piling up at the center
+func ageRange(age time.Duration) float64 {
+	frac := math.Log1p(max(age.Seconds(), 0)) / math.Log1p(radarMaxAge.Seconds())
+	return 0.2 + 0.8*min(frac, 1)
+}
+
+// setConnections updates the table and the radar. Connections that have
+// gone away stay on the radar for radarFade, dimming as they go.
+func (m *model) setConnections(msg connectionsMsg) {
+	now := m.now()
+	selected := ""
+	if m.connTable.cursor < len(m.conns) {
+		selected = m.conns[m.connTable.cursor].key()
+	}
+
+	conns := append([]connInfo(nil), msg.conns...)
+	sort.Slice(conns, func(i, j int) bool {
+		if conns[i].Process != conns[j].Process {
+			return conns[i].Process < conns[j].Process
+		}
+		return conns[i].key() < conns[j].key()
+	})
+
+	seen := make(map[string]time.Time, len(conns))
+	rows := make([][]string, len(conns))
+	blips := make([]radarBlip, 0, len(conns)+len(m.blips))
+	cursor := 0
+	for i, c := range conns {
+		k := c.key()
+		first, ok := m.connSeen[k]
+		if !ok {
+			first = now
+		}
+		seen[k] = first
+		age := now.Sub(first)
+		blips = append(blips, radarBlip{key: k, angle: peerBearing(c.remoteHost()), dist: ageRange(age)})
+
+		process := c.Process
+		if process == "" {
+			process = "-"
+		}
+		pid := "-"
+		if c.PID > 0 {
+			pid = strconv.Itoa(int(c.PID))
+		}
+		rows[i] = []string{c.Proto, c.Local, c.Remote, c.Status, pid, process, formatAge(age)}
+		if k == selected {
+			cursor = i
+		}
+	}
+
+	// Keep fading the ones that closed
+	for _, b := range m.blips {
+		if _, open := seen[b.key]; open {
+			continue
+		}
+		if b.closedAt.IsZero() {
+			b.closedAt = now
+		}
+		if now.Sub(b.closedAt) < radarFade {
+			blips = append(blips, b)
+		}
+	}
+
+	m.conns = conns
+	m.connErr = msg.err
+	m.connSeen = seen
+	m.blips = blips
+	m.connTable.setRows(rows)
+	m.connTable.setCursor(cursor)
+	if msg.err == nil {
+		// A failed read says nothing about the listeners; keep the last
+		// list so services don't alert again when reads recover
+		m.setListeners(msg.listeners)
+	}
+}
+
+// formatAge is a compact duration: 42s, 7m, 3h, 2d
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	}
+	return fmt.Sprintf("%dd", int(d.Hours()/24))
+}
+
+func (m model) renderConnectionsPanel(width, height int) string {
+	title := fmt.Sprintf("CONNECTIONS · %d PEERS", m.peerCount())
+	if m.connErr != nil {
+		title += " · " + m.connErr.Error()
+	}
+	title = runewidth.Truncate(title, max(width-4, 1), "…")
+	return m.renderTablePanel(panelConnections, title, m.connTable, width, height)
+}
+
+func (m model) peerCount() int {
+	hosts := make(map[string]bool)
+	for _, c := range m.conns {
+		hosts[c.remoteHost()] = true
+	}
+	return len(hosts)
+}
+
+// Radar geometry: a 13x7 character scope, twice as wide as tall so it
+// looks round in a terminal
+const (
+	radarCols = 13
+	radarRows = 7
+)
+
+// renderRadar plots every blip on a sweeping scope. A blip is brightest
+// just after the sweep passes it and dims until the next pass.
+func (m model) renderRadar() string {
+	theme := m.getTheme()
+	ring := lipgloss.NewStyle().Foreground(gridColor)
+	sweep := lipgloss.NewStyle().Foreground(alertGreen).Faint(true)
+	center := lipgloss.NewStyle().Foreground(theme.Primary).Bold(true)
+	blipStyles := []lipgloss.Style{
+		lipgloss.NewStyle().Foreground(alertGreen).Bold(true),
+		lipgloss.NewStyle().Foreground(alertGreen),
+		lipgloss.NewStyle().Foreground(lipgloss.Color("#1F8F1F")),
+		lipgloss.NewStyle().Foreground(lipgloss.Color("#145214")),
+	}
+	blipChars := []string{"●", "●", "•", "·"}
+
+	type cell struct {
+		char  string
+		style lipgloss.Style
+		level int // lower wins when blips share a cell
+	}
+	grid := make([][]cell, radarRows)
+	cx, cy := float64(radarCols-1)/2, float64(radarRows-1)/2
+	for y := range grid {
+		grid[y] = make([]cell, radarCols)
+		for x := range grid[y] {
+			grid[y][x] = cell{char: " ", level: len(blipStyles)}
+			dx, dy := (float64(x)-cx)/cx, (float64(y)-cy)/cy
+			d := math.Hypot(dx, dy)
+			if math.Abs(d-1) < 0.12 {
+				grid[y][x].char, grid[y][x].style = "·", ring
+			}
+			// The sweep arm trails the current bearing
+			a := math.Mod(math.Atan2(dx, -dy)*180/math.Pi+360, 360)
+			if d < 0.95 && d > 0 && angleBehind(m.radarSweep, a) < radarSweepStep*1.5 {
+				grid[y][x].char, grid[y][x].style = "░", sweep
+			}
+		}
+	}
+	grid[int(cy)][int(cx)] = cell{char: "◉", style: center, level: len(blipStyles)}
+
+	now := m.now()
+	for _, b := range m.blips {
+		// Fresh from the sweep is level 0; a full turn later, level 3
+		level := int(angleBehind(m.radarSweep, b.angle) / 360 * float64(len(blipStyles)))
+		if !b.closedAt.IsZero() {
+			faded := now.Sub(b.closedAt).Seconds() / radarFade.Seconds()
+			level = max(level, 1+int(faded*float64(len(blipStyles)-1)))
+		}
+		level = min(level, len(blipStyles)-1)
+
+		rad := b.angle * math.Pi / 180
+		x := int(math.Round(cx + math.Sin(rad)*b.dist*cx))
+		y := int(math.Round(cy - math.Cos(rad)*b.dist*cy))
+		if x < 0 || x >= radarCols || y < 0 || y >= radarRows || (x == int(cx) && y == int(cy)) {
+			continue
+		}
+		if level <= grid[y][x].level {
+			grid[y][x] = cell{char: blipChars[level], style: blipStyles[level], level: level}
+		}
+	}
+
+	lines := make([]string, radarRows)
+	for y, row := range grid {
+		var sb strings.Builder
+		for _, c := range row {
+			if c.char == " " {
+				sb.WriteString(" ")
+				continue
+			}
+			sb.WriteString(c.style.Render(c.char))
+		}
+		lines[y] = sb.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// angleBehind is how many degrees the sweep has turned since passing a
+func angleBehind(sweep, a float64) float64 {
+	return math.Mod(sweep-a+720, 360)
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func testConns() connectionsMsg {
+	return connectionsMsg{conns: []connInfo{
+		{Proto: "tcp", Local: "10.0.0.5:51234", Remote: "140.82.112.4:443", Status: "ESTABLISHED", PID: 1337, Process: "jarvis"},
+		{Proto: "tcp", Local: "10.0.0.5:51236", Remote: "140.82.112.4:443", Status: "TIME_WAIT"},
+		{Proto: "tcp6", Local: "[fd00::5]:40112", Remote: "[2606:4700::1111]:853", Status: "ESTABLISHED", PID: 420, Process: "arc-reactor"},
+		{Proto: "udp", Local: "10.0.0.5:5353", Remote: "10.0.0.1:53", Status: "-", PID: 1, Process: "init"},
+	}, listeners: testListeners()}
+}
+
+func TestRadarGeometry(t *testing.T) {
+	if a, b := peerBearing("140.82.112.4"), peerBearing("140.82.112.4"); a != b || a < 0 || a >= 360 {
+		t.Errorf("bearing = %v, %v", a, b)
+	}
+	if peerBearing("140.82.112.4") == peerBearing("10.0.0.1") {
+		t.Error("different peers share a bearing")
+	}
+
+	// Range grows with age, log scaled, and stops at the rim
+	prev := ageRange(0)
+	if prev != 0.2 {
+		t.Errorf("new connection at %v, want 0.2", prev)
+	}
+	for _, age := range []time.Duration{time.Second, 10 * time.Second, time.Minute, radarMaxAge} {
+		d := ageRange(age)
+		if d <= prev {
+			t.Errorf("range at %v = %v, not beyond %v", age, d, prev)
+		}
+		prev = d
+	}
+	if d := ageRange(time.Hour); d != 1 {
+		t.Errorf("range past radarMaxAge = %v, want 1", d)
+	}
+	if got := angleBehind(10, 350); got != 20 {
+		t.Errorf("angleBehind wraps to %v, want 20", got)
+	}
+}
+
+func TestConnectionsTrackAgeAndFade(t *testing.T) {
+	m := newTestModel(t, 120, 40)
+	clock := testTime
+	m.now = func() time.Time { return clock }
+
+	m = step(m, testConns())
+	if len(m.blips) != 4 || m.peerCount() != 3 {
+		t.Fatalf("blips = %d, peers = %d", len(m.blips), m.peerCount())
+	}
+	// Sorted by process, unknown owners first
+	if got := m.connTable.rows[0]; got[4] != "-" || got[5] != "-" || got[6] != "0s" {
+		t.Errorf("first row = %v", got)
+	}
+	m.connTable.setCursor(3) // jarvis
+
+	// A minute on, the jarvis connection is still open and has moved out;
+	// the TIME_WAIT one has closed
+	clock = clock.Add(time.Minute)
+	msg := testConns()
+	msg.conns = append(msg.conns[:1], msg.conns[2:]...)
+	m = step(m, msg)
+	if row := m.connTable.rows[m.connTable.cursor]; row[5] != "jarvis" || row[6] != "1m" {
+		t.Errorf("selection moved to %v", row)
+	}
+	if len(m.blips) != 4 {
+		t.Fatalf("closed connection left the radar at once: %d blips", len(m.blips))
+	}
+	for _, b := range m.blips {
+		switch {
+		case strings.Contains(b.key, "51236"):
+			if b.closedAt != clock {
+				t.Errorf("closed blip = %+v", b)
+			}
+		case b.dist != ageRange(time.Minute):
+			t.Errorf("open blip %s at %v, want %v", b.key, b.dist, ageRange(time.Minute))
+		}
+	}
+
+	// Once faded it is gone
+	clock = clock.Add(radarFade)
+	m = step(m, msg)
+	if len(m.blips) != 3 {
+		t.Errorf("blips after fade = %d, want 3", len(m.blips))
+	}
+}
+
+func TestRadarPlotsBlips(t *testing.T) {
+	m := newTestModel(t, 120, 40)
+	empty := ansi.Strip(m.renderRadar())
+	if strings.ContainsAny(empty, "●•") {
+		t.Errorf("radar without connections has blips:\n%s", empty)
+	}
+
+	m = step(m, testConns())
+	// Just behind the sweep a blip is at its brightest
+	m.radarSweep = peerBearing("10.0.0.1") + 1
+	if got := ansi.Strip(m.renderRadar()); !strings.Contains(got, "●") {
+		t.Errorf("radar does not plot the peer:\n%s", got)
+	}
+	if lines := strings.Split(ansi.Strip(m.renderRadar()), "\n"); len(lines) != radarRows {
+		t.Errorf("radar is %d rows, want %d", len(lines), radarRows)
+	}
+}
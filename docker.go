@@ -0,0 +1,489 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-runewidth"
+)
+
+// DockerConfig points the Containers page at a Docker Engine socket
+type DockerConfig struct {
+	Socket string `json:"socket"` // default /var/run/docker.sock
+}
+
+const (
+	defaultDockerSocket = "/var/run/docker.sock"
+	dockerTimeout       = 5 * time.Second
+	dockerLogTail       = 50 // lines of history shown when tailing starts
+)
+
+// containerInfo is one row of the Containers page
+type containerInfo struct {
+	ID       string
+	Name     string
+	Image    string
+	State    string // created, running, paused, restarting, exited, dead
+	Status   string // human summary, e.g. "Up 3 hours"
+	CPU      float64
+	MemUsage uint64
+	MemLimit uint64
+	RxBytes  uint64
+	TxBytes  uint64
+}
+
+type containersMsg struct {
+	containers []containerInfo
+	err        error
+}
+
+// containerActionMsg reports a start/stop/restart request
+type containerActionMsg struct {
+	name, action string
+	err          error
+}
+
+// dockerClient speaks the Engine HTTP API over a Unix socket. The API is
+// called unversioned so any daemon answers with its own version.
+type dockerClient struct {
+	socket string
+	http   *http.Client
+	stream *http.Client // no timeout, for following logs
+
+	mu      sync.Mutex
+	prevCPU map[string]dockerCPU // last CPU counters per container id
+}
+
+// dockerCPU is a container's cumulative CPU time against the host's
+type dockerCPU struct {
+	container, system uint64
+}
+
+func newDockerClient(cfg *DockerConfig) *dockerClient {
+	socket := defaultDockerSocket
+	if cfg != nil && cfg.Socket != "" {
+		socket = cfg.Socket
+	}
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", socket)
+		},
+	}
+	return &dockerClient{
+		socket:  socket,
+		http:    &http.Client{Transport: transport, Timeout: dockerTimeout},
+		stream:  &http.Client{Transport: transport},
+		prevCPU: make(map[string]dockerCPU),
+	}
+}
+
+// do sends a request and fails on any non-2xx status, using the daemon's
+// error message when it sends one
+func (d *dockerClient) do(client *http.Client, method, path string, query url.Values) (*http.Response, error) {
+	u := url.URL{Scheme: "http", Host: "docker", Path: path, RawQuery: query.Encode()}
+	req, err := http.NewRequest(method, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		var opErr *net.OpError
+		if errors.As(err, &opErr) {
+			return nil, fmt.Errorf("docker socket %s: %w", d.socket, opErr.Err)
+		}
+		return nil, err
+	}
+	if res.StatusCode/100 == 2 || res.StatusCode == http.StatusNotModified {
+		return res, nil
+	}
+	defer res.Body.Close()
+	var body struct {
+		Message string `json:"message"`
+	}
+	if json.NewDecoder(io.LimitReader(res.Body, 64<<10)).Decode(&body) == nil && body.Message != "" {
+		return nil, errors.New(body.Message)
+	}
+	return nil, fmt.Errorf("docker: %s", res.Status)
+}
+
+func (d *dockerClient) getJSON(path string, query url.Values, v any) error {
+	res, err := d.do(d.http, http.MethodGet, path, query)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	return json.NewDecoder(res.Body).Decode(v)
+}
+
+// dockerContainer is an entry of GET /containers/json
+type dockerContainer struct {
+	ID     string   `json:"Id"`
+	Names  []string `json:"Names"`
+	Image  string   `json:"Image"`
+	State  string   `json:"State"`
+	Status string   `json:"Status"`
+}
+
+// dockerStats is the part of GET /containers/{id}/stats we read
+type dockerStats struct {
+	CPUStats struct {
+		CPUUsage struct {
+			TotalUsage  uint64   `json:"total_usage"`
+			PercpuUsage []uint64 `json:"percpu_usage"`
+		} `json:"cpu_usage"`
+		SystemUsage uint64 `json:"system_cpu_usage"`
+		OnlineCPUs  int    `json:"online_cpus"`
+	} `json:"cpu_stats"`
+	MemoryStats struct {
+		Usage uint64            `json:"usage"`
+		Limit uint64            `json:"limit"`
+		Stats map[string]uint64 `json:"stats"`
+	} `json:"memory_stats"`
+	Networks map[string]struct {
+		RxBytes uint64 `json:"rx_bytes"`
+		TxBytes uint64 `json:"tx_bytes"`
+	} `json:"networks"`
+}
+
+// list returns every container, with live stats for the running ones
+func (d *dockerClient) list() ([]containerInfo, error) {
+	var raw []dockerContainer
+	if err := d.getJSON("/containers/json", url.Values{"all": {"1"}}, &raw); err != nil {
+		return nil, err
+	}
+
+	infos := make([]containerInfo, len(raw))
+	var wg sync.WaitGroup
+	for i, c := range raw {
+		name := c.ID[:min(12, len(c.ID))]
+		if len(c.Names) > 0 {
+			name = strings.TrimPrefix(c.Names[0], "/")
+		}
+		infos[i] = containerInfo{ID: c.ID, Name: name, Image: c.Image, State: c.State, Status: c.Status}
+		if c.State != "running" {
+			continue
+		}
+		// Stats are one request per container, so fetch them in parallel
+		wg.Add(1)
+		go func(info *containerInfo) {
+			defer wg.Done()
+			d.fillStats(info)
+		}(&infos[i])
+	}
+	wg.Wait()
+	d.forgetExcept(infos)
+
+	sort.Slice(infos, func(i, j int) bool {
+		if (infos[i].State == "running") != (infos[j].State == "running") {
+			return infos[i].State == "running"
+		}
+		return infos[i].Name < infos[j].Name
+	})
+	return infos, nil
+}
+
+// fillStats reads one stats sample. one-shot skips the daemon's own
+// second sample, so CPU is measured against our previous reading instead.
+func (d *dockerClient) fillStats(info *containerInfo) {
+	var s dockerStats
+	q := url.Values{"stream": {"false"}, "one-shot": {"true"}}
+	if err := d.getJSON("/containers/"+info.ID+"/stats", q, &s); err != nil {
+		return // the container may have stopped since the listing
+	}
+
+	cur := dockerCPU{container: s.CPUStats.CPUUsage.TotalUsage, system: s.CPUStats.SystemUsage}
+	cpus := s.CPUStats.OnlineCPUs
+	if cpus == 0 {
+		cpus = max(len(s.CPUStats.CPUUsage.PercpuUsage), 1)
+	}
+	d.mu.Lock()
+	prev, ok := d.prevCPU[info.ID]
+	d.prevCPU[info.ID] = cur
+	d.mu.Unlock()
+	if ok && cur.system > prev.system && cur.container >= prev.container {
+		info.CPU = float64(cur.container-prev.container) / float64(cur.system-prev.system) * float64(cpus) * 100
+	}
+
+	// Page cache is reclaimable, so docker stats leaves it out too
+	// (inactive_file on cgroup v2, cache on v1)
+	cache := s.MemoryStats.Stats["inactive_file"]
+	if cache == 0 {
+		cache = s.MemoryStats.Stats["cache"]
+	}
+	info.MemUsage = s.MemoryStats.Usage - min(cache, s.MemoryStats.Usage)
+	info.MemLimit = s.MemoryStats.Limit
+	for _, n := range s.Networks {
+		info.RxBytes += n.RxBytes
+		info.TxBytes += n.TxBytes
+	}
+}
+
+// forgetExcept drops CPU history for containers that no longer exist
+func (d *dockerClient) forgetExcept(infos []containerInfo) {
+	live := make(map[string]bool, len(infos))
+	for _, c := range infos {
+		live[c.ID] = true
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for id := range d.prevCPU {
+		if !live[id] {
+			delete(d.prevCPU, id)
+		}
+	}
+}
+
+// action starts, stops or restarts a container
+func (d *dockerClient) action(id, action string) error {
+	res, err := d.do(d.http, http.MethodPost, "/containers/"+id+"/"+action, nil)
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+	return nil
+}
+
+// logs follows a container's stdout and stderr, starting with the last
+// few lines
+func (d *dockerClient) logs(id string) (*containerLogStream, error) {
+	q := url.Values{"stdout": {"1"}, "stderr": {"1"}, "follow": {"1"}, "tail": {fmt.Sprint(dockerLogTail)}}
+	res, err := d.do(d.stream, http.MethodGet, "/containers/"+id+"/logs", q)
+	if err != nil {
+		return nil, err
+	}
+	return &containerLogStream{body: res.Body, r: bufio.NewReader(res.Body)}, nil
+}
+
+// containerLogStream splits a log response into lines. Containers without
+// a TTY multiplex stdout and stderr behind 8-byte frame headers; TTY
+// containers send the raw stream.
+type containerLogStream struct {
+	id, name string
+	body     io.ReadCloser
+	r        *bufio.Reader
+	framed   *bool  // decided from the first bytes
+	frame    int    // unread bytes left in the current frame
+	partial  []byte // text read so far of an unfinished line
+	pending  []string
+}
+
+func (s *containerLogStream) Close() error {
+	if s.body == nil {
+		return nil // a replayed stream
+	}
+	return s.body.Close()
+}
+
+// next returns the next complete log line
+func (s *containerLogStream) next() (string, error) {
+	for len(s.pending) == 0 {
+		if err := s.fill(); err != nil {
+			if len(s.partial) > 0 {
+				line := string(s.partial)
+				s.partial = nil
+				return line, nil
+			}
+			return "", err
+		}
+	}
+	line := s.pending[0]
+	s.pending = s.pending[1:]
+	return line, nil
+}
+
+// fill reads the next chunk of payload and splits off any complete lines
+func (s *containerLogStream) fill() error {
+	if s.framed == nil {
+		hdr, err := s.r.Peek(8)
+		// A frame header is a stream id of 0-2 followed by three zero bytes
+		framed := err == nil && hdr[0] <= 2 && hdr[1] == 0 && hdr[2] == 0 && hdr[3] == 0
+		s.framed = &framed
+	}
+
+	var chunk []byte
+	if *s.framed {
+		if s.frame == 0 {
+			var hdr [8]byte
+			if _, err := io.ReadFull(s.r, hdr[:]); err != nil {
+				return err
+			}
+			s.frame = int(binary.BigEndian.Uint32(hdr[4:]))
+			return nil
+		}
+		chunk = make([]byte, min(s.frame, 4096))
+		n, err := s.r.Read(chunk)
+		s.frame -= n
+		chunk = chunk[:n]
+		if err != nil && n == 0 {
+			return err
+		}
+	} else {
+		chunk = make([]byte, 4096)
+		n, err := s.r.Read(chunk)
+		chunk = chunk[:n]
+		if err != nil && n == 0 {
+			return err
+		}
+	}
+
+	s.partial = append(s.partial, chunk...)
+	for {
+		i := bytes.IndexByte(s.partial, '\n')
+		if i < 0 {
+			break
+		}
+		s.pending = append(s.pending, strings.TrimRight(string(s.partial[:i]), "\r"))
+		s.partial = s.partial[i+1:]
+	}
+	return nil
+}
+
+// containerLogMsg carries one line from the followed container
+type containerLogMsg struct {
+	stream *containerLogStream
+	line   string
+	err    error
+}
+
+// containerLogStartedMsg hands a freshly opened log stream to Update
+type containerLogStartedMsg struct {
+	stream *containerLogStream
+	err    error
+}
+
+func readContainerLogCommand(s *containerLogStream) tea.Cmd {
+	return func() tea.Msg {
+		line, err := s.next()
+		return containerLogMsg{stream: s, line: line, err: err}
+	}
+}
+
+// --- Model glue ---
+
+func collectContainersCommand(d *dockerClient) tea.Cmd {
+	return func() tea.Msg {
+		containers, err := d.list()
+		return containersMsg{containers: containers, err: err}
+	}
+}
+
+func (m *model) setContainers(msg containersMsg) {
+	// Keep the selection on the same container across refreshes
+	selected := ""
+	if c, ok := m.selectedContainer(); ok {
+		selected = c.ID
+	}
+
+	m.containers = msg.containers
+	m.containerErr = msg.err
+	rows := make([][]string, len(msg.containers))
+	cursor := 0
+	for i, c := range msg.containers {
+		cpu, mem, net := "-", "-", "-"
+		if c.State == "running" {
+			cpu = fmt.Sprintf("%.1f", c.CPU)
+			mem = formatBytes(c.MemUsage)
+			if c.MemLimit > 0 {
+				mem += " / " + formatBytes(c.MemLimit)
+			}
+			net = formatBytes(c.RxBytes) + " / " + formatBytes(c.TxBytes)
+		}
+		rows[i] = []string{c.Name, c.Image, c.Status, cpu, mem, net}
+		if c.ID == selected {
+			cursor = i
+		}
+	}
+	m.containerTable.setRows(rows)
+	m.containerTable.setCursor(cursor)
+}
+
+func (m model) selectedContainer() (containerInfo, bool) {
+	if m.containerTable.cursor < len(m.containers) {
+		return m.containers[m.containerTable.cursor], true
+	}
+	return containerInfo{}, false
+}
+
+// containerAction runs start, stop or restart on the selected container
+func (m *model) containerAction(action string) tea.Cmd {
+	c, ok := m.selectedContainer()
+	if m.docker == nil || !ok {
+		return nil
+	}
+	return m.containerActionFor(c, action)
+}
+
+func (m *model) containerActionFor(c containerInfo, action string) tea.Cmd {
+	m.addLog(fmt.Sprintf("Container %s: %s requested", c.Name, action))
+	d := m.docker
+	return func() tea.Msg {
+		return containerActionMsg{name: c.Name, action: action, err: d.action(c.ID, action)}
+	}
+}
+
+// toggleContainerLogs follows the selected container's logs into the
+// telemetry stream, or stops following if it already is
+func (m *model) toggleContainerLogs() tea.Cmd {
+	c, ok := m.selectedContainer()
+	if m.docker == nil || !ok {
+		return nil
+	}
+	return m.toggleContainerLogsFor(c)
+}
+
+func (m *model) toggleContainerLogsFor(c containerInfo) tea.Cmd {
+	if m.logTail != nil {
+		following := m.logTail.id
+		m.stopContainerLogs()
+		if following == c.ID {
+			return nil
+		}
+	}
+	m.addLog(fmt.Sprintf("Following logs of %s", c.Name))
+	d := m.docker
+	return func() tea.Msg {
+		s, err := d.logs(c.ID)
+		if s != nil {
+			s.id, s.name = c.ID, c.Name
+		}
+		return containerLogStartedMsg{stream: s, err: err}
+	}
+}
+
+func (m *model) stopContainerLogs() {
+	if m.logTail == nil {
+		return
+	}
+	m.addLog(fmt.Sprintf("Stopped following logs of %s", m.logTail.name))
+	m.logTail.Close()
+	m.logTail = nil
+}
+
+// renderContainersPanel is a table panel whose header carries the daemon's
+// error, if it can't be reached
+func (m model) renderContainersPanel(width, height int) string {
+	title := "CONTAINERS"
+	if m.logTail != nil {
+		title += " · FOLLOWING " + m.logTail.name
+	}
+	if m.containerErr != nil {
+		title += " · " + m.containerErr.Error()
+	}
+	title = runewidth.Truncate(title, max(width-4, 1), "…")
+	return m.renderTablePanel(panelContainers, title, m.containerTable, width, height)
+}
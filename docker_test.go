@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// fakeDocker answers the handful of Engine API calls the Containers page
+// makes, over a Unix socket like the real daemon
+type fakeDocker struct {
+	mu      sync.Mutex
+	polls   int
+	actions []string
+}
+
+func startFakeDocker(t *testing.T) (*fakeDocker, string) {
+	t.Helper()
+
+	f := &fakeDocker{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /containers/json", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("all") != "1" {
+			t.Errorf("list query = %q, want all=1", r.URL.RawQuery)
+		}
+		json.NewEncoder(w).Encode([]dockerContainer{
+			{ID: "db0000000000ffff", Names: []string{"/db"}, Image: "postgres:16", State: "exited", Status: "Exited (0) 2 hours ago"},
+			{ID: "web000000000ffff", Names: []string{"/web"}, Image: "nginx:1.27", State: "running", Status: "Up 3 hours"},
+		})
+	})
+	mux.HandleFunc("GET /containers/{id}/stats", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		f.polls++
+		n := uint64(f.polls)
+		f.mu.Unlock()
+		// Each poll the container uses 0.2s of every 1s of host CPU time
+		io.WriteString(w, `{
+			"cpu_stats": {"cpu_usage": {"total_usage": `+fmt.Sprint(n*200_000_000)+`}, "system_cpu_usage": `+fmt.Sprint(n*1_000_000_000)+`, "online_cpus": 2},
+			"memory_stats": {"usage": 314572800, "limit": 1073741824, "stats": {"inactive_file": 104857600}},
+			"networks": {"eth0": {"rx_bytes": 2048, "tx_bytes": 1024}, "eth1": {"rx_bytes": 2048, "tx_bytes": 0}}
+		}`)
+	})
+	mux.HandleFunc("POST /containers/{id}/{action}", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		f.actions = append(f.actions, r.PathValue("action")+" "+r.PathValue("id"))
+		f.mu.Unlock()
+		if r.PathValue("id") == "db0000000000ffff" && r.PathValue("action") == "start" {
+			w.WriteHeader(http.StatusInternalServerError)
+			io.WriteString(w, `{"message":"port 5432 is already allocated"}`)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("GET /containers/{id}/logs", func(w http.ResponseWriter, r *http.Request) {
+		if q := r.URL.Query(); q.Get("follow") != "1" || q.Get("tail") != "50" {
+			t.Errorf("logs query = %q", r.URL.RawQuery)
+		}
+		// A line split across frames, then one on stderr
+		writeLogFrame(w, 1, "listening on :80\nGET /ind")
+		writeLogFrame(w, 1, "ex.html 200\n")
+		writeLogFrame(w, 2, "worker exited\n")
+	})
+
+	socket := filepath.Join(t.TempDir(), "docker.sock")
+	ln, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &http.Server{Handler: mux}
+	go srv.Serve(ln)
+	t.Cleanup(func() { srv.Close() })
+	return f, socket
+}
+
+func writeLogFrame(w io.Writer, stream byte, text string) {
+	hdr := make([]byte, 8)
+	hdr[0] = stream
+	binary.BigEndian.PutUint32(hdr[4:], uint32(len(text)))
+	w.Write(append(hdr, text...))
+}
+
+func TestDockerClient(t *testing.T) {
+	f, socket := startFakeDocker(t)
+	d := newDockerClient(&DockerConfig{Socket: socket})
+
+	cs, err := d.list()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cs) != 2 || cs[0].Name != "web" || cs[1].Name != "db" {
+		t.Fatalf("list = %+v, want web (running) before db", cs)
+	}
+	web := cs[0]
+	// The first sample has nothing to compare against
+	if web.CPU != 0 || web.MemUsage != 200<<20 || web.MemLimit != 1<<30 || web.RxBytes != 4096 || web.TxBytes != 1024 {
+		t.Errorf("web = %+v", web)
+	}
+	if cs[1].MemUsage != 0 || f.polls != 1 {
+		t.Errorf("stopped container was polled: %+v, %d polls", cs[1], f.polls)
+	}
+
+	cs, _ = d.list()
+	if cs[0].CPU != 40 {
+		t.Errorf("CPU = %v, want 40 (0.2s per second on 2 CPUs)", cs[0].CPU)
+	}
+
+	if err := d.action(web.ID, "restart"); err != nil {
+		t.Errorf("restart: %v", err)
+	}
+	if err := d.action(cs[1].ID, "start"); err == nil || err.Error() != "port 5432 is already allocated" {
+		t.Errorf("start db: err = %v, want the daemon's message", err)
+	}
+	if got := strings.Join(f.actions, ", "); got != "restart web000000000ffff, start db0000000000ffff" {
+		t.Errorf("actions = %s", got)
+	}
+}
+
+func TestContainerLogs(t *testing.T) {
+	_, socket := startFakeDocker(t)
+
+	m := newTestModel(t, 120, 40)
+	m.docker = newDockerClient(&DockerConfig{Socket: socket})
+	m = step(m, collectContainersCommand(m.docker)())
+	m.setPage(pageContainers)
+
+	// Follow the selected container and pump its stream into Update
+	tm, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("L")})
+	m = tm.(model)
+	msg := cmd()
+	for i := 0; i < 10; i++ {
+		tm, cmd := m.Update(msg)
+		m = tm.(model)
+		if cmd == nil {
+			break
+		}
+		msg = cmd()
+	}
+
+	logs := strings.Join(m.logs, "\n")
+	for _, want := range []string{"[web] listening on :80", "[web] GET /index.html 200", "[web] worker exited", "Log stream of web ended"} {
+		if !strings.Contains(logs, want) {
+			t.Errorf("telemetry is missing %q", want)
+		}
+	}
+	if m.logTail != nil {
+		t.Error("ended stream is still followed")
+	}
+}
+
+func TestContainerLogStreamRaw(t *testing.T) {
+	// TTY containers send plain text without frame headers
+	s := &containerLogStream{body: io.NopCloser(strings.NewReader("")), r: bufio.NewReader(strings.NewReader("booting\r\nready\npartial"))}
+	for _, want := range []string{"booting", "ready", "partial"} {
+		if line, err := s.next(); err != nil || line != want {
+			t.Errorf("next = %q %v, want %q", line, err, want)
+		}
+	}
+	if _, err := s.next(); err != io.EOF {
+		t.Errorf("after the last line err = %v, want EOF", err)
+	}
+}
+
+func TestDockerMissingSocket(t *testing.T) {
+	m := newTestModel(t, 120, 40)
+	m.docker = newDockerClient(&DockerConfig{Socket: filepath.Join(t.TempDir(), "nope.sock")})
+	m = step(m, collectContainersCommand(m.docker)())
+	m.setPage(pageContainers)
+
+	if m.containerErr == nil || !strings.Contains(m.containerErr.Error(), "nope.sock") {
+		t.Errorf("err = %v, want it to name the socket", m.containerErr)
+	}
+	if !strings.Contains(m.View(), "no such file") {
+		t.Error("panel header does not show why the daemon is unreachable")
+	}
+}
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	psnet "github.com/shirou/gopsutil/v3/net"
+)
+
+// exporter serves the latest collected values in the Prometheus text
+// exposition format. Update pushes into it as messages arrive; the HTTP
+// handler reads under the same lock, so the model itself is never shared.
+// All methods are no-ops on a nil exporter, which is the default.
+type exporter struct {
+	mu         sync.Mutex
+	sample     Sample
+	hasSample  bool
+	ifaces     []psnet.IOCountersStat
+	disks      []diskInfo
+	collectors map[string]*collectorHealth
+
+	alertsTotal   [4]uint64 // indexed by severity
+	unacked       [4]int
+	alertActive   bool
+	alertSeverity int
+}
+
+type collectorHealth struct {
+	up          bool
+	lastSuccess time.Time
+	errors      uint64
+}
+
+// Collector names used in jarvis_collector_* labels
+const (
+	collectorSample     = "vitals"
+	collectorProcesses  = "processes"
+	collectorDisks      = "disks"
+	collectorInterfaces = "interfaces"
+)
+
+func newExporter() *exporter {
+	return &exporter{collectors: make(map[string]*collectorHealth)}
+}
+
+// observe records the outcome of one collector run; callers hold mu
+func (e *exporter) observe(name string, ok bool, at time.Time) {
+	h := e.collectors[name]
+	if h == nil {
+		h = &collectorHealth{}
+		e.collectors[name] = h
+	}
+	h.up = ok
+	if ok {
+		h.lastSuccess = at
+	} else {
+		h.errors++
+	}
+}
+
+func (e *exporter) setSample(s Sample, err error, at time.Time) {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err == nil {
+		e.sample, e.hasSample = s, true
+	}
+	e.observe(collectorSample, err == nil, at)
+}
+
+func (e *exporter) setProcesses(err error, at time.Time) {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.observe(collectorProcesses, err == nil, at)
+}
+
+func (e *exporter) setDisks(disks []diskInfo, at time.Time) {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if disks != nil {
+		e.disks = disks
+	}
+	e.observe(collectorDisks, disks != nil, at)
+}
+
+func (e *exporter) setIfaces(stats []psnet.IOCountersStat, at time.Time) {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if stats != nil {
+		e.ifaces = stats
+	}
+	e.observe(collectorInterfaces, stats != nil, at)
+}
+
+func (e *exporter) alertRaised(severity int) {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if severity >= 0 && severity < len(e.alertsTotal) {
+		e.alertsTotal[severity]++
+	}
+}
+
+// setAlerts mirrors the alert history and the HUD alert state
+func (e *exporter) setAlerts(history []alertRecord, active bool, severity int) {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.unacked = [4]int{}
+	for _, a := range history {
+		if !a.Acked && a.Severity >= 0 && a.Severity < len(e.unacked) {
+			e.unacked[a.Severity]++
+		}
+	}
+	e.alertActive, e.alertSeverity = active, severity
+}
+
+// publishAlerts pushes the current alert state to the exporter
+func (m *model) publishAlerts() {
+	m.exporter.setAlerts(m.alertHistory, m.alertActive, m.alertSeverity)
+}
+
+func (e *exporter) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	e.write(w)
+}
+
+// write renders every metric family in exposition format
+func (e *exporter) write(w io.Writer) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var b strings.Builder
+	family := func(name, typ, help string) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+	}
+	sample := func(name string, value float64, labels ...string) {
+		b.WriteString(name)
+		if len(labels) > 0 {
+			b.WriteByte('{')
+			for i := 0; i < len(labels); i += 2 {
+				if i > 0 {
+					b.WriteByte(',')
+				}
+				fmt.Fprintf(&b, "%s=\"%s\"", labels[i], escapeLabel(labels[i+1]))
+			}
+			b.WriteByte('}')
+		}
+		fmt.Fprintf(&b, " %g\n", value)
+	}
+
+	if e.hasSample {
+		family("jarvis_cpu_usage_ratio", "gauge", "CPU usage across all cores (0-1).")
+		sample("jarvis_cpu_usage_ratio", e.sample.CPUPercent/100)
+		family("jarvis_memory_used_ratio", "gauge", "Fraction of physical memory in use (0-1).")
+		sample("jarvis_memory_used_ratio", e.sample.MemUsedPercent/100)
+	}
+
+	if len(e.ifaces) > 0 {
+		family("jarvis_network_receive_bytes_total", "counter", "Bytes received per interface.")
+		for _, s := range e.ifaces {
+			sample("jarvis_network_receive_bytes_total", float64(s.BytesRecv), "interface", s.Name)
+		}
+		family("jarvis_network_transmit_bytes_total", "counter", "Bytes sent per interface.")
+		for _, s := range e.ifaces {
+			sample("jarvis_network_transmit_bytes_total", float64(s.BytesSent), "interface", s.Name)
+		}
+		family("jarvis_network_errors_total", "counter", "Receive and transmit errors per interface.")
+		for _, s := range e.ifaces {
+			sample("jarvis_network_errors_total", float64(s.Errin+s.Errout), "interface", s.Name)
+		}
+	}
+
+	if len(e.disks) > 0 {
+		family("jarvis_filesystem_size_bytes", "gauge", "Filesystem size.")
+		for _, d := range e.disks {
+			sample("jarvis_filesystem_size_bytes", float64(d.Total), "mountpoint", d.Mount, "fstype", d.FSType)
+		}
+		family("jarvis_filesystem_used_bytes", "gauge", "Filesystem space in use.")
+		for _, d := range e.disks {
+			sample("jarvis_filesystem_used_bytes", float64(d.Used), "mountpoint", d.Mount, "fstype", d.FSType)
+		}
+		family("jarvis_filesystem_free_bytes", "gauge", "Filesystem space available.")
+		for _, d := range e.disks {
+			sample("jarvis_filesystem_free_bytes", float64(d.Free), "mountpoint", d.Mount, "fstype", d.FSType)
+		}
+	}
+
+	family("jarvis_alert_active", "gauge", "Whether an alert is showing on the HUD, by severity.")
+	for sev := 1; sev < len(e.alertsTotal); sev++ {
+		sample("jarvis_alert_active", boolValue(e.alertActive && e.alertSeverity == sev), "severity", strings.ToLower(severityName(sev)))
+	}
+	family("jarvis_alerts_total", "counter", "Alerts raised since start, by severity.")
+	for sev := 1; sev < len(e.alertsTotal); sev++ {
+		sample("jarvis_alerts_total", float64(e.alertsTotal[sev]), "severity", strings.ToLower(severityName(sev)))
+	}
+	family("jarvis_alerts_unacknowledged", "gauge", "Unacknowledged alerts in the history, by severity.")
+	for sev := 1; sev < len(e.unacked); sev++ {
+		sample("jarvis_alerts_unacknowledged", float64(e.unacked[sev]), "severity", strings.ToLower(severityName(sev)))
+	}
+
+	names := make([]string, 0, len(e.collectors))
+	for name := range e.collectors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if len(names) > 0 {
+		family("jarvis_collector_up", "gauge", "Whether the collector's last run succeeded.")
+		for _, name := range names {
+			sample("jarvis_collector_up", boolValue(e.collectors[name].up), "collector", name)
+		}
+		family("jarvis_collector_errors_total", "counter", "Failed collector runs since start.")
+		for _, name := range names {
+			sample("jarvis_collector_errors_total", float64(e.collectors[name].errors), "collector", name)
+		}
+		family("jarvis_collector_last_success_timestamp_seconds", "gauge", "Unix time of the collector's last successful run.")
+		for _, name := range names {
+			var ts float64
+			if t := e.collectors[name].lastSuccess; !t.IsZero() {
+				ts = float64(t.UnixMilli()) / 1000
+			}
+			sample("jarvis_collector_last_success_timestamp_seconds", ts, "collector", name)
+		}
+	}
+
+	io.WriteString(w, b.String())
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(s string) string {
+	return labelEscaper.Replace(s)
+}
+
+// serveMetrics starts the /metrics endpoint on addr. The listener is opened
+// before returning so a bad address or busy port is reported at startup.
+func serveMetrics(addr string, e *exporter) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", e)
+	go http.Serve(ln, mux)
+	return nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	psnet "github.com/shirou/gopsutil/v3/net"
+)
+
+func TestExporterMetrics(t *testing.T) {
+	m := newTestModel(t, 120, 40)
+	m.exporter = newExporter()
+
+	m = step(m, sampleMsg{sample: Sample{CPUPercent: 25, MemUsedPercent: 50}})
+	m = step(m, testDisks())
+	m = step(m, ifacesMsg{at: testTime, stats: []psnet.IOCountersStat{{Name: "eth0", BytesRecv: 1024, BytesSent: 2048, Errin: 1}}})
+	m = step(m, processesMsg{err: errors.New("permission denied")})
+	m = step(m, sampleMsg{err: errors.New("boom")})
+	m.raiseAlert("Hull breach", 3)
+	m.raiseAlert("Power fluctuation", 1)
+
+	rec := httptest.NewRecorder()
+	m.exporter.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	body, _ := io.ReadAll(rec.Body)
+	got := string(body)
+
+	for _, want := range []string{
+		"# TYPE jarvis_cpu_usage_ratio gauge\njarvis_cpu_usage_ratio 0.25\n",
+		"jarvis_memory_used_ratio 0.5\n",
+		`jarvis_network_receive_bytes_total{interface="eth0"} 1024` + "\n",
+		`jarvis_network_transmit_bytes_total{interface="eth0"} 2048` + "\n",
+		`jarvis_network_errors_total{interface="eth0"} 1` + "\n",
+		`jarvis_filesystem_used_bytes{mountpoint="/",fstype="ext4"} 3.221225472e+11` + "\n",
+		`jarvis_alert_active{severity="warning"} 1` + "\n",
+		`jarvis_alert_active{severity="critical"} 0` + "\n",
+		`jarvis_alerts_total{severity="critical"} 1` + "\n",
+		`jarvis_alerts_unacknowledged{severity="warning"} 1` + "\n",
+		`jarvis_collector_up{collector="disks"} 1` + "\n",
+		`jarvis_collector_up{collector="processes"} 0` + "\n",
+		`jarvis_collector_up{collector="vitals"} 0` + "\n",
+		`jarvis_collector_errors_total{collector="vitals"} 1` + "\n",
+		`jarvis_collector_last_success_timestamp_seconds{collector="interfaces"} 1.741944413e+09` + "\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("metrics missing %q", want)
+		}
+	}
+	if t.Failed() {
+		t.Logf("exposition:\n%s", got)
+	}
+
+	// The last good sample is kept when a later one fails
+	if !strings.Contains(got, "jarvis_cpu_usage_ratio 0.25") {
+		t.Error("failed sample replaced the last good value")
+	}
+}
+
+// Prometheus vitals describe a cluster, not this node, so they stay out of
+// the node's gauges while its disks are still exported
+func TestExporterSkipsClusterVitals(t *testing.T) {
+	m := newTestModel(t, 120, 40)
+	m.exporter = newExporter()
+	src, err := newPrometheusSource(PrometheusConfig{URL: "http://prometheus:9090"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.source = src
+
+	m = step(m, sampleMsg{sample: Sample{CPUPercent: 99}})
+	m = step(m, testDisks())
+
+	rec := httptest.NewRecorder()
+	m.exporter.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	got := rec.Body.String()
+	if strings.Contains(got, "jarvis_cpu_usage_ratio 0.99") {
+		t.Error("cluster CPU exported as this node's")
+	}
+	if !strings.Contains(got, `jarvis_filesystem_used_bytes{mountpoint="/"`) {
+		t.Errorf("local disks missing:\n%s", got)
+	}
+}
+
+func TestEscapeLabel(t *testing.T) {
+	if got, want := escapeLabel("a\"b\\c\nd"), `a\"b\\c\nd`; got != want {
+		t.Errorf("escapeLabel = %q, want %q", got, want)
+	}
+}
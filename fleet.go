@@ -0,0 +1,555 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+)
+
+// AgentConfig names one host running `jarvis agent`
+type AgentConfig struct {
+	Name  string `json:"name"`
+	Addr  string `json:"addr"` // host or host:port, port defaults to 7787
+	Token string `json:"token"`
+}
+
+// Reconnect backoff doubles from agentMinBackoff up to agentMaxBackoff and
+// resets once an agent accepts the handshake
+const (
+	agentMinBackoff = time.Second
+	agentMaxBackoff = 30 * time.Second
+)
+
+type agentState int
+
+const (
+	agentConnecting agentState = iota
+	agentOnline
+	agentOffline
+	agentRejected // the agent refused our token or protocol version
+)
+
+// agentRejectedError carries the reason from an agent's error frame
+type agentRejectedError string
+
+func (e agentRejectedError) Error() string {
+	return "rejected: " + string(e)
+}
+
+// agentConn keeps a connection to one agent alive in the background and
+// holds its latest snapshot for the UI to pick up
+type agentConn struct {
+	cfg  AgentConfig
+	stop chan struct{}
+
+	mu    sync.Mutex
+	state agentState
+	err   error
+	host  string
+	snap  *agentSnapshot
+	prev  *agentSnapshot
+}
+
+func (c *agentConn) run() {
+	backoff := agentMinBackoff
+	for {
+		welcomed, err := c.session()
+
+		c.mu.Lock()
+		c.err = err
+		c.state = agentOffline
+		var rejected agentRejectedError
+		if errors.As(err, &rejected) {
+			c.state = agentRejected
+		}
+		c.mu.Unlock()
+
+		if welcomed {
+			backoff = agentMinBackoff
+		}
+		select {
+		case <-c.stop:
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, agentMaxBackoff)
+	}
+}
+
+// session runs one connection from dial to hangup. It reports whether the
+// agent got as far as welcoming us.
+func (c *agentConn) session() (bool, error) {
+	conn, err := net.DialTimeout("tcp", c.cfg.Addr, agentHandshakeTimeout)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	// Unblock reads when the fleet shuts down
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-c.stop:
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	conn.SetDeadline(time.Now().Add(agentHandshakeTimeout))
+	if err := writeFrame(conn, frameHello, agentHello{Token: c.cfg.Token, Client: "jarvis"}); err != nil {
+		return false, err
+	}
+	t, payload, err := readFrame(conn, maxAgentFrame)
+	if t == frameError {
+		var e agentError
+		if json.Unmarshal(payload, &e) == nil {
+			return false, agentRejectedError(e.Error)
+		}
+	}
+	if err != nil {
+		return false, err
+	}
+	if t != frameWelcome {
+		return false, fmt.Errorf("unexpected frame type %d during handshake", t)
+	}
+	var welcome agentWelcome
+	if err := json.Unmarshal(payload, &welcome); err != nil {
+		return false, fmt.Errorf("malformed welcome: %w", err)
+	}
+	interval := time.Duration(welcome.IntervalMS) * time.Millisecond
+	if interval <= 0 {
+		interval = defaultAgentInterval
+	}
+
+	c.mu.Lock()
+	c.state = agentOnline
+	c.host = welcome.Host
+	c.err = nil
+	c.mu.Unlock()
+
+	for {
+		// An agent that misses three pushes in a row is treated as gone
+		conn.SetReadDeadline(time.Now().Add(3 * interval))
+		t, payload, err := readFrame(conn, maxAgentFrame)
+		if err != nil {
+			return true, err
+		}
+		if t != frameSnapshot {
+			continue
+		}
+		var snap agentSnapshot
+		if err := json.Unmarshal(payload, &snap); err != nil {
+			return true, fmt.Errorf("malformed snapshot: %w", err)
+		}
+
+		c.mu.Lock()
+		c.prev, c.snap = c.snap, &snap
+		c.mu.Unlock()
+	}
+}
+
+// latest returns the newest snapshot while the agent is online
+func (c *agentConn) latest() (*agentSnapshot, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch {
+	case c.state == agentOnline && c.snap != nil:
+		return c.snap, nil
+	case c.err != nil:
+		return nil, fmt.Errorf("%s: %w", c.cfg.Name, c.err)
+	}
+	return nil, fmt.Errorf("%s: waiting for agent", c.cfg.Name)
+}
+
+// collectCommands feeds the agent's latest snapshot through the same
+// messages the local collectors produce, so every panel shows the host
+func (c *agentConn) collectCommands() []tea.Cmd {
+	snap, err := c.latest()
+	if err != nil {
+		return []tea.Cmd{func() tea.Msg { return sampleMsg{err: err} }}
+	}
+	var sampleErr error
+	if snap.SampleErr != "" {
+		sampleErr = errors.New(snap.SampleErr)
+	}
+	return []tea.Cmd{
+		func() tea.Msg { return sampleMsg{sample: snap.Sample, err: sampleErr} },
+		func() tea.Msg { return processesMsg{procs: snap.Processes} },
+		func() tea.Msg { return disksMsg(snap.Disks) },
+		func() tea.Msg { return ifacesMsg{at: snap.Time, stats: snap.Ifaces} },
+	}
+}
+
+// hostStatus is a copy of one agent's state for rendering
+type hostStatus struct {
+	Name    string
+	Host    string
+	State   agentState
+	Err     string
+	Sample  Sample
+	HasData bool
+	RxRate  float64 // bytes per second
+	TxRate  float64
+	Alerts  []agentAlert
+}
+
+func (c *agentConn) status() hostStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	st := hostStatus{Name: c.cfg.Name, Host: c.host, State: c.state}
+	if c.err != nil {
+		st.Err = c.err.Error()
+	}
+	if c.snap == nil {
+		return st
+	}
+	st.HasData = true
+	st.Sample = c.snap.Sample
+	st.Alerts = c.snap.Alerts
+	if c.prev != nil {
+		elapsed := c.snap.Time.Sub(c.prev.Time).Seconds()
+		cur, prev := c.snap.Sample, c.prev.Sample
+		if elapsed > 0 && cur.NetBytesRecv >= prev.NetBytesRecv && cur.NetBytesSent >= prev.NetBytesSent {
+			st.RxRate = float64(cur.NetBytesRecv-prev.NetBytesRecv) / elapsed
+			st.TxRate = float64(cur.NetBytesSent-prev.NetBytesSent) / elapsed
+		}
+	}
+	return st
+}
+
+// fleet is the set of agents named in the config
+type fleet struct {
+	agents []*agentConn
+}
+
+func newFleet(cfgs []AgentConfig) (*fleet, error) {
+	if len(cfgs) == 0 {
+		return nil, nil
+	}
+	f := &fleet{}
+	seen := make(map[string]bool)
+	for i, cfg := range cfgs {
+		if cfg.Addr == "" {
+			return nil, fmt.Errorf("agent %d: addr is required", i+1)
+		}
+		if _, _, err := net.SplitHostPort(cfg.Addr); err != nil {
+			cfg.Addr = net.JoinHostPort(cfg.Addr, defaultAgentPort)
+		}
+		if cfg.Name == "" {
+			cfg.Name, _, _ = net.SplitHostPort(cfg.Addr)
+		}
+		if cfg.Token == "" {
+			return nil, fmt.Errorf("agent %s: token is required", cfg.Name)
+		}
+		if seen[cfg.Name] {
+			return nil, fmt.Errorf("agent %s: duplicate name", cfg.Name)
+		}
+		seen[cfg.Name] = true
+		f.agents = append(f.agents, &agentConn{cfg: cfg, stop: make(chan struct{})})
+	}
+	return f, nil
+}
+
+// start connects to every agent in the background
+func (f *fleet) start() {
+	if f == nil {
+		return
+	}
+	for _, a := range f.agents {
+		go a.run()
+	}
+}
+
+// close disconnects every agent
+func (f *fleet) close() {
+	if f == nil {
+		return
+	}
+	for _, a := range f.agents {
+		close(a.stop)
+	}
+}
+
+func (f *fleet) statuses() []hostStatus {
+	if f == nil {
+		return nil
+	}
+	hosts := make([]hostStatus, len(f.agents))
+	for i, a := range f.agents {
+		hosts[i] = a.status()
+	}
+	return hosts
+}
+
+// fleetMsg refreshes the fleet grid
+type fleetMsg []hostStatus
+
+func collectFleetCommand(f *fleet) tea.Cmd {
+	return func() tea.Msg {
+		return fleetMsg(f.statuses())
+	}
+}
+
+// --- Fleet page ---
+
+// Grid cards include their border. Card 0 is this machine; agents follow
+// in config order.
+const (
+	fleetCardWidth  = 30
+	fleetCardHeight = 7
+)
+
+// fleetGrid returns the number of card columns and visible rows for a
+// panel's inner size, and the first row shown so the cursor stays in view
+func (m model) fleetGrid(width, height int) (cols, rows, top int) {
+	cols = max((width-2)/fleetCardWidth, 1)
+	// Padding plus the panel header and its margin sit above the grid
+	rows = max((height-4)/fleetCardHeight, 1)
+	top = max(m.fleetCursor/cols-rows+1, 0)
+	return cols, rows, top
+}
+
+// fleetCardAt returns the card under a point relative to the panel's top-left corner
+func (m model) fleetCardAt(x, y, width, height int) (int, bool) {
+	cols, rows, top := m.fleetGrid(width-2, height-2)
+	x, y = x-2, y-tableTop
+	if x < 0 || y < 0 || x >= cols*fleetCardWidth || y >= rows*fleetCardHeight {
+		return 0, false
+	}
+	idx := (top+y/fleetCardHeight)*cols + x/fleetCardWidth
+	if idx > len(m.hosts) {
+		return 0, false
+	}
+	return idx, true
+}
+
+func (m *model) moveFleetCursor(delta int) {
+	m.fleetCursor = min(max(m.fleetCursor+delta, 0), len(m.hosts))
+}
+
+// openHost points the whole dashboard at a fleet card: 0 is this
+// machine, n is the nth configured agent
+func (m *model) openHost(idx int) tea.Cmd {
+	var next *agentConn
+	if idx > 0 {
+		if m.fleet == nil || idx > len(m.fleet.agents) {
+			return nil
+		}
+		next = m.fleet.agents[idx-1]
+	}
+	m.setPage(pageOverview)
+	if next == m.remote {
+		return nil
+	}
+
+	// Drop the previous host's data so tables never mix two machines
+	m.remote = next
+	m.setProcesses(nil)
+	m.procErr = nil
+	m.setDisks(nil)
+	m.setIfaces(ifacesMsg{})
+	m.setContainers(containersMsg{})
+	m.setThermal(thermalMsg{})
+	m.setPower(powerMsg{})
+	m.memory = memoryMsg{}
+	m.setConnections(connectionsMsg{})
+	m.resonance, m.lastSampleAt = nil, time.Time{}
+	m.lastDiskIO, m.diskReadRate, m.diskWriteRate = diskIOMsg{}, 0, 0
+	m.stopContainerLogs()
+	if next == nil {
+		m.addLog("Dashboard switched to local host")
+	} else {
+		m.addLog(fmt.Sprintf("Dashboard switched to agent %s", next.cfg.Name))
+	}
+	return tea.Batch(append(m.backgroundCollectCommands(), m.resonanceHistoryCommand())...)
+}
+
+// hostName is the title-bar label for the host the dashboard is showing,
+// empty for this machine
+func (m model) hostName() string {
+	if m.remote == nil {
+		if src, ok := m.source.(HostSource); ok {
+			return src.Host()
+		}
+		return ""
+	}
+	return m.remote.cfg.Name
+}
+
+func (m model) renderFleetPanel(width, height int) string {
+	theme := m.getTheme()
+
+	online := 0
+	for _, h := range m.hosts {
+		if h.State == agentOnline {
+			online++
+		}
+	}
+	header := headerStyle.Render(fmt.Sprintf("FLEET STATUS  %d/%d ONLINE", online, len(m.hosts)))
+
+	cards := make([]string, 0, len(m.hosts)+1)
+	cards = append(cards, m.renderLocalCard())
+	for i, h := range m.hosts {
+		cards = append(cards, m.renderHostCard(i+1, h))
+	}
+
+	cols, rows, top := m.fleetGrid(width, height)
+	var lines []string
+	for r := top; r < top+rows && r*cols < len(cards); r++ {
+		end := min((r+1)*cols, len(cards))
+		lines = append(lines, lipgloss.JoinHorizontal(lipgloss.Top, cards[r*cols:end]...))
+	}
+
+	blocks := []string{header}
+	blocks = append(blocks, lines...)
+	if len(m.hosts) == 0 {
+		blocks = append(blocks, lipgloss.NewStyle().Foreground(theme.Dim).Width(max(width-2, 1)).
+			Render(`No agents configured. Run "jarvis agent" on each host and list them under "agents" in the config file.`))
+	}
+
+	content := lipgloss.JoinVertical(lipgloss.Left, blocks...)
+	return m.panelStyle(panelFleet).Width(width).Height(height).MaxHeight(height + 2).Render(content)
+}
+
+// fleetCardStyle frames a card, highlighting the one under the cursor
+func (m model) fleetCardStyle(idx int) lipgloss.Style {
+	theme := m.getTheme()
+	style := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(theme.Dim).
+		Width(fleetCardWidth - 2).
+		Height(fleetCardHeight - 2)
+	if idx == m.fleetCursor && m.focus == panelFleet {
+		style = style.Border(lipgloss.ThickBorder()).BorderForeground(theme.Primary)
+	}
+	return style
+}
+
+func (m model) renderLocalCard() string {
+	theme := m.getTheme()
+	const inner = fleetCardWidth - 2
+
+	lines := []string{m.cardTitle("LOCAL", badgePurple.Render("VIEWING"), m.remote == nil)}
+	dim := lipgloss.NewStyle().Foreground(theme.Dim)
+	if m.remote == nil {
+		lines = append(lines,
+			cardBar("CPU", m.cpuVal*100, inner),
+			cardBar("MEM", m.memVal*100, inner),
+		)
+	} else {
+		lines = append(lines, dim.Render("This machine"), "", dim.Render("Enter to return here"))
+	}
+	return m.fleetCardStyle(0).Render(strings.Join(lines, "\n"))
+}
+
+func (m model) renderHostCard(idx int, h hostStatus) string {
+	theme := m.getTheme()
+	const inner = fleetCardWidth - 2
+
+	var badge string
+	switch {
+	case m.remote != nil && m.remote.cfg.Name == h.Name:
+		badge = badgePurple.Render("VIEWING")
+	case h.State == agentOnline:
+		badge = badgeGreen.Render("ONLINE")
+	case h.State == agentOffline:
+		badge = badgeRed.Render("OFFLINE")
+	case h.State == agentRejected:
+		badge = badgeRed.Render("REJECTED")
+	default:
+		badge = lipgloss.NewStyle().Foreground(theme.Dim).Render("CONNECTING…")
+	}
+
+	lines := []string{m.cardTitle(h.Name, badge, true)}
+	dim := lipgloss.NewStyle().Foreground(theme.Dim)
+
+	if !h.HasData {
+		msg := h.Err
+		if msg == "" {
+			msg = "waiting for first snapshot"
+		}
+		lines = append(lines, dim.Width(inner).Render(runewidth.Truncate(msg, inner*3, "…")))
+		return m.fleetCardStyle(idx).Render(strings.Join(lines, "\n"))
+	}
+
+	body := []string{
+		cardBar("CPU", h.Sample.CPUPercent, inner),
+		cardBar("MEM", h.Sample.MemUsedPercent, inner),
+		fmt.Sprintf("NET ↓%s/s ↑%s/s", formatBytes(uint64(h.RxRate)), formatBytes(uint64(h.TxRate))),
+	}
+	if h.State != agentOnline {
+		// Keep the last known values visible, dimmed, above the error
+		for i := range body {
+			body[i] = dim.Render(body[i])
+		}
+		lines = append(lines, body...)
+		lines = append(lines, lipgloss.NewStyle().Foreground(alertRed).Render(runewidth.Truncate(h.Err, inner, "…")))
+		return m.fleetCardStyle(idx).Render(strings.Join(lines, "\n"))
+	}
+	lines = append(lines, body...)
+	lines = append(lines, hostAlertBadges(h.Alerts, inner))
+	return m.fleetCardStyle(idx).Render(strings.Join(lines, "\n"))
+}
+
+// cardTitle puts a host name on the left of a card and a badge on the right
+func (m model) cardTitle(name, badge string, showBadge bool) string {
+	const inner = fleetCardWidth - 2
+	if !showBadge {
+		badge = ""
+	}
+	title := lipgloss.NewStyle().Foreground(m.getTheme().Primary).Bold(true).
+		Render(runewidth.Truncate(name, max(inner-lipgloss.Width(badge)-1, 1), "…"))
+	gap := max(inner-lipgloss.Width(title)-lipgloss.Width(badge), 1)
+	return title + strings.Repeat(" ", gap) + badge
+}
+
+// cardBar draws a labelled percentage bar filling a card row
+func cardBar(label string, pct float64, width int) string {
+	return fmt.Sprintf("%s %s %3.0f%%", label, usageBar(pct/100, width-9), pct)
+}
+
+// hostAlertBadges shows the alerts an agent reports, critical (red) ones
+// first, then warnings (yellow), as many as fit in width
+func hostAlertBadges(alerts []agentAlert, width int) string {
+	if len(alerts) == 0 {
+		return badgeGreen.Render("NO ALERTS")
+	}
+	alerts = slices.Clone(alerts)
+	slices.SortStableFunc(alerts, func(a, b agentAlert) int { return b.Severity - a.Severity })
+
+	var badges []string
+	used := 0
+	for i, a := range alerts {
+		style := badgeYellow
+		if a.Severity >= 3 {
+			style = badgeRed
+		}
+		badge := style.Render(a.Name)
+		// Leave room to count the alerts that do not fit
+		need := lipgloss.Width(badge)
+		if i < len(alerts)-1 {
+			need += len(" +9")
+		}
+		if used > 0 {
+			need++
+		}
+		if used+need > width {
+			badges = append(badges, fmt.Sprintf("+%d", len(alerts)-i))
+			break
+		}
+		badges = append(badges, badge)
+		used += lipgloss.Width(badge) + 1
+	}
+	return strings.Join(badges, " ")
+}
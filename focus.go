@@ -0,0 +1,60 @@
+package main
+
+import "github.com/charmbracelet/lipgloss"
+
+// cycleFocus moves focus around the ring of panels. While zoomed the
+// maximized panel follows focus.
+func (m *model) cycleFocus(delta int) {
+	ring := m.focusRing()
+	if len(ring) == 0 {
+		return
+	}
+
+	current := 0
+	for i, id := range ring {
+		if id == m.focus {
+			current = i
+			break
+		}
+	}
+
+	next := (current + delta + len(ring)) % len(ring)
+	m.focus = ring[next]
+	if m.zoomed {
+		m.resize()
+	}
+}
+
+// toggleZoom maximizes the focused panel or restores the normal layout
+func (m *model) toggleZoom() {
+	m.zoomed = !m.zoomed
+	m.resize()
+}
+
+// panelStyle returns the box style for a panel, highlighting it when focused
+func (m model) panelStyle(id panelID) lipgloss.Style {
+	if id != m.focus {
+		return boxStyle
+	}
+	return boxStyle.
+		Border(lipgloss.ThickBorder()).
+		BorderForeground(m.getTheme().Primary)
+}
+
+// scrollPanel scrolls the scrollable content of a panel by delta lines
+func (m *model) scrollPanel(id panelID, delta int) {
+	switch id {
+	case panelTelemetry:
+		if delta < 0 {
+			m.viewport.LineUp(-delta)
+		} else {
+			m.viewport.LineDown(delta)
+		}
+	case panelFleet:
+		m.moveFleetCursor(delta)
+	}
+
+	if t := m.tableFor(id); t != nil {
+		t.moveCursor(delta)
+	}
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// Golden frames pin View() output at fixed sizes and themes. After an
+// intentional visual change, regenerate them with:
+//
+//	go test -run TestGolden -update
+var update = flag.Bool("update", false, "rewrite golden files")
+
+func TestMain(m *testing.M) {
+	// Render full color regardless of the terminal running the tests
+	lipgloss.SetColorProfile(termenv.TrueColor)
+	os.Exit(m.Run())
+}
+
+// fakeSource returns a fixed sample
+type fakeSource struct {
+	sample Sample
+}
+
+func (f fakeSource) Sample() (Sample, error) {
+	return f.sample, nil
+}
+
+var testTime = time.Date(2025, time.March, 14, 9, 26, 53, 0, time.UTC)
+
+// newTestModel builds a model with a fixed clock, seeded RNG and fake
+// metrics, sized to width x height
+func newTestModel(t *testing.T, width, height int) model {
+	t.Helper()
+
+	m := initialModel()
+	m.now = func() time.Time { return testTime }
+	m.rng = rand.New(rand.NewSource(1))
+	m.source = fakeSource{Sample{CPUPercent: 42, MemUsedPercent: 63.5, NetBytesSent: 250_000, NetBytesRecv: 125_000}}
+
+	m = step(m, tea.WindowSizeMsg{Width: width, Height: height})
+	return step(m, collectSampleCommand(m.source)())
+}
+
+// step feeds one message through Update, discarding commands
+func step(m model, msg tea.Msg) model {
+	tm, _ := m.Update(msg)
+	return tm.(model)
+}
+
+func testProcesses() []processInfo {
+	return []processInfo{
+		{PID: 1, Name: "init", CPU: 0.1, Mem: 0.2, RSS: 12 << 20},
+		{PID: 420, Name: "arc-reactor", CPU: 87.5, Mem: 12.25, RSS: 1536 << 20},
+		{PID: 1337, Name: "jarvis", CPU: 12.3, Mem: 4.5, RSS: 256 << 20},
+	}
+}
+
+func testDisks() disksMsg {
+	return disksMsg{
+		{Mount: "/", FSType: "ext4", Total: 512 << 30, Used: 300 << 30, Free: 212 << 30, UsedPercent: 58.6},
+		{Mount: "/boot", FSType: "vfat", Total: 1 << 30, Used: 100 << 20, Free: 924 << 20, UsedPercent: 9.8},
+	}
+}
+
+func TestGolden(t *testing.T) {
+	cases := []struct {
+		name          string
+		width, height int
+		theme         int
+		setup         func(m model) model
+	}{
+		{name: "overview_80x24", width: 80, height: 24},
+		{name: "overview_120x40", width: 120, height: 40},
+		{name: "overview_160x48", width: 160, height: 48},
+		{name: "overview_stealth_120x40", width: 120, height: 40, theme: 2},
+		{name: "overview_warmachine_160x48", width: 160, height: 48, theme: 4},
+		{name: "processes_120x40", width: 120, height: 40, setup: func(m model) model {
+			m = step(m, processesMsg{procs: testProcesses()})
+			m = step(m, testMemory())
+			m.setPage(pageProcesses)
+			return m
+		}},
+		{name: "storage_120x40", width: 120, height: 40, setup: func(m model) model {
+			m = step(m, testDisks())
+			m.setPage(pageStorage)
+			return m
+		}},
+		{name: "alerts_120x40", width: 120, height: 40, setup: func(m model) model {
+			m.raiseAlert("UNAUTHORIZED ACCESS ATTEMPT", 3)
+			m.raiseAlert("Power fluctuation detected", 1)
+			m.acknowledgeAlert()
+			m.showAlerts = true
+			return m
+		}},
+		{name: "help_120x40", width: 120, height: 40, setup: func(m model) model {
+			m.showHelp = true
+			return m
+		}},
+		{name: "zoomed_120x40", width: 120, height: 40, setup: func(m model) model {
+			m.toggleZoom()
+			return m
+		}},
+		{name: "fleet_120x40", width: 120, height: 40, setup: func(m model) model {
+			m.fleet, _ = newFleet([]AgentConfig{
+				{Name: "web-1", Addr: "10.0.0.11", Token: "t"},
+				{Name: "db-1", Addr: "10.0.0.21", Token: "t"},
+				{Name: "edge", Addr: "10.0.0.31", Token: "t"},
+				{Name: "batch", Addr: "10.0.0.41", Token: "t"},
+			})
+			m = step(m, fleetMsg{
+				{Name: "web-1", Host: "web-1", State: agentOnline, HasData: true, Sample: Sample{CPUPercent: 23, MemUsedPercent: 41}, RxRate: 1.5 * 1024 * 1024, TxRate: 300 * 1024},
+				{Name: "db-1", Host: "db-1", State: agentOnline, HasData: true, Sample: Sample{CPUPercent: 94, MemUsedPercent: 82}, RxRate: 20 * 1024, TxRate: 4 * 1024 * 1024, Alerts: []agentAlert{{"CPU", 3}, {"MEM", 1}, {"DISK", 3}, {"PORTS", 2}}},
+				{Name: "edge", State: agentRejected, Err: "rejected: invalid token"},
+				{Name: "batch", Host: "batch", State: agentOffline, Err: "read tcp: i/o timeout", HasData: true, Sample: Sample{CPUPercent: 12, MemUsedPercent: 30}},
+			})
+			m.setPage(pageFleet)
+			m.scrollPanel(panelFleet, 2)
+			return m
+		}},
+		{name: "containers_120x40", width: 120, height: 40, setup: func(m model) model {
+			m = step(m, containersMsg{containers: []containerInfo{
+				{ID: "a1", Name: "api", Image: "registry.local/api:2.4.1", State: "running", Status: "Up 3 days", CPU: 12.5, MemUsage: 312 << 20, MemLimit: 2 << 30, RxBytes: 1 << 30, TxBytes: 220 << 20},
+				{ID: "b2", Name: "postgres", Image: "postgres:16", State: "running", Status: "Up 3 days (healthy)", CPU: 3.1, MemUsage: 1 << 30, RxBytes: 80 << 20, TxBytes: 900 << 20},
+				{ID: "c3", Name: "migrate", Image: "registry.local/api:2.4.1", State: "exited", Status: "Exited (0) 3 days ago"},
+			}})
+			m.setPage(pageContainers)
+			return m
+		}},
+		{name: "sensors_120x40", width: 120, height: 40, setup: func(m model) model {
+			m = step(m, testThermal())
+			m = step(m, testPower())
+			m.setPage(pageSensors)
+			return m
+		}},
+		{name: "network_120x40", width: 120, height: 40, setup: func(m model) model {
+			m = step(m, testConns())
+			m.setPage(pageNetwork)
+			return m
+		}},
+		{name: "ports_120x40", width: 120, height: 40, setup: func(m model) model {
+			m.portAllow, _ = newPortAllowlist(&PortsConfig{Allow: []string{"sshd"}})
+			m = step(m, connectionsMsg{listeners: testListeners()})
+			m.setPage(pagePorts)
+			return m
+		}},
+		{name: "services_120x40", width: 120, height: 40, setup: func(m model) model {
+			f := func(v float64) *float64 { return &v }
+			m.widgets, _ = newWidgets([]WidgetConfig{
+				{Name: "API LATENCY", URL: "http://api/health", Display: "bar", Unit: "ms", Max: f(500), Warn: f(200), Crit: f(400)},
+				{Name: "QUEUE DEPTH", URL: "http://queue/stats", Display: "gauge", Max: f(1000), Warn: f(800), Crit: f(950)},
+				{Name: "AUTH", URL: "http://auth/health", Display: "badge", OK: []string{"UP"}},
+				{Name: "BUILD", URL: "http://ci/status", Display: "text"},
+				{Name: "BILLING", URL: "http://billing/health", Display: "badge"},
+			})
+			m = step(m, widgetResultMsg{idx: 0, value: 250.0})
+			m = step(m, widgetResultMsg{idx: 1, value: 420.0})
+			m = step(m, widgetResultMsg{idx: 2, value: "DOWN"})
+			m = step(m, widgetResultMsg{idx: 4, err: errors.New("timed out after 5s")})
+			m.setPage(pageServices)
+			return m
+		}},
+		{name: "ticks_120x40", width: 120, height: 40, setup: func(m model) model {
+			// Animation, jitter and the audio bars all draw from m.rng
+			for i := 0; i < 25; i++ {
+				m = step(m, tickMsg(testTime))
+			}
+			return m
+		}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := newTestModel(t, tc.width, tc.height)
+			m.setTheme(tc.theme)
+			if tc.setup != nil {
+				m = tc.setup(m)
+			}
+			checkGolden(t, tc.name, m.View())
+		})
+	}
+}
+
+// checkGolden compares got against testdata/golden/<name>.golden
+func checkGolden(t *testing.T, name, got string) {
+	t.Helper()
+
+	path := filepath.Join("testdata", "golden", name+".golden")
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading golden file (run with -update to create it): %v", err)
+	}
+	if got != string(want) {
+		t.Errorf("frame differs from %s (run with -update if the change is intended)\n--- got ---\n%s\n--- want ---\n%s", path, got, want)
+	}
+}
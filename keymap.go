@@ -0,0 +1,192 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+)
+
+// keyMap defines every global keybinding. The help overlay and the command
+// palette both read from it, so overrides show up everywhere.
+type keyMap struct {
+	Quit       key.Binding
+	Help       key.Binding
+	Close      key.Binding
+	Palette    key.Binding
+	Theme      key.Binding
+	Pause      key.Binding
+	SoundWave  key.Binding
+	Reboot     key.Binding
+	Scan       key.Binding
+	Alerts     key.Binding
+	FocusNext  key.Binding
+	FocusPrev  key.Binding
+	Zoom       key.Binding
+	Open       key.Binding
+	Start      key.Binding
+	Stop       key.Binding
+	Restart    key.Binding
+	Logs       key.Binding
+	Pages      []key.Binding
+	ScrollUp   key.Binding
+	ScrollDown key.Binding
+}
+
+// namedBinding pairs a binding with the action name used in the config file
+type namedBinding struct {
+	name    string
+	binding *key.Binding
+}
+
+func defaultKeyMap() keyMap {
+	pageKeys := make([]key.Binding, len(pages))
+	for i, p := range pages {
+		pageKeys[i] = newBinding(strings.ToUpper(p.name[:1])+strings.ToLower(p.name[1:])+" Page", pageKey(i))
+	}
+
+	return keyMap{
+		Pages:      pageKeys,
+		Quit:       newBinding("Exit Application", "q", "ctrl+c"),
+		Help:       newBinding("Toggle This Help", "h", "?"),
+		Close:      newBinding("Close Overlay", "esc"),
+		Palette:    newBinding("Command Palette", "ctrl+p"),
+		Theme:      newBinding("Cycle Themes", "t"),
+		Pause:      newBinding("Pause/Resume", "p"),
+		SoundWave:  newBinding("Toggle Sound Wave", "s"),
+		Reboot:     newBinding("Reboot System", "r"),
+		Scan:       newBinding("Manual Scan", " "),
+		Alerts:     newBinding("Alert History", "a"),
+		FocusNext:  newBinding("Focus Next Panel", "tab"),
+		FocusPrev:  newBinding("Focus Previous Panel", "shift+tab"),
+		Zoom:       newBinding("Zoom Focused Panel", "z"),
+		Open:       newBinding("Open Selected Host", "enter"),
+		Start:      newBinding("Start Container", "U"),
+		Stop:       newBinding("Stop Container", "X"),
+		Restart:    newBinding("Restart Container", "R"),
+		Logs:       newBinding("Follow Container Logs", "L"),
+		ScrollUp:   newBinding("Scroll Up", "up"),
+		ScrollDown: newBinding("Scroll Down", "down"),
+	}
+}
+
+func newBinding(desc string, keys ...string) key.Binding {
+	return key.NewBinding(key.WithKeys(keys...), key.WithHelp(formatKeys(keys), desc))
+}
+
+// bindings lists the keymap entries in help order
+func (k *keyMap) bindings() []namedBinding {
+	bindings := []namedBinding{
+		{"quit", &k.Quit},
+		{"help", &k.Help},
+		{"close", &k.Close},
+		{"palette", &k.Palette},
+		{"theme", &k.Theme},
+		{"pause", &k.Pause},
+		{"sound_wave", &k.SoundWave},
+		{"reboot", &k.Reboot},
+		{"scan", &k.Scan},
+		{"alerts", &k.Alerts},
+		{"focus_next", &k.FocusNext},
+		{"focus_prev", &k.FocusPrev},
+		{"zoom", &k.Zoom},
+		{"open", &k.Open},
+		{"container_start", &k.Start},
+		{"container_stop", &k.Stop},
+		{"container_restart", &k.Restart},
+		{"container_logs", &k.Logs},
+		{"scroll_up", &k.ScrollUp},
+		{"scroll_down", &k.ScrollDown},
+	}
+	for i := range k.Pages {
+		bindings = append(bindings, namedBinding{"page_" + strings.ToLower(pages[i].name), &k.Pages[i]})
+	}
+	return bindings
+}
+
+// applyOverrides rebinds actions from the config and rejects unknown
+// action names or overrides that leave two actions on the same key
+func (k *keyMap) applyOverrides(overrides map[string][]string) error {
+	byName := make(map[string]*key.Binding)
+	for _, nb := range k.bindings() {
+		byName[nb.name] = nb.binding
+	}
+
+	for name, keys := range overrides {
+		b, ok := byName[name]
+		if !ok {
+			return fmt.Errorf("unknown key action %q", name)
+		}
+		if len(keys) == 0 {
+			b.SetEnabled(false)
+			continue
+		}
+		b.SetKeys(keys...)
+		b.SetHelp(formatKeys(keys), b.Help().Desc)
+	}
+
+	if conflicts := k.conflicts(); len(conflicts) > 0 {
+		return fmt.Errorf("conflicting keybindings: %s", strings.Join(conflicts, "; "))
+	}
+	return nil
+}
+
+// conflicts describes every key that is bound to more than one action
+func (k *keyMap) conflicts() []string {
+	owners := make(map[string][]string)
+	for _, nb := range k.bindings() {
+		if !nb.binding.Enabled() {
+			continue
+		}
+		for _, key := range nb.binding.Keys() {
+			owners[key] = append(owners[key], nb.name)
+		}
+	}
+
+	var conflicts []string
+	for key, names := range owners {
+		if len(names) > 1 {
+			conflicts = append(conflicts, fmt.Sprintf("%s -> %s", formatKey(key), strings.Join(names, ", ")))
+		}
+	}
+	sort.Strings(conflicts)
+	return conflicts
+}
+
+// formatKeys renders a binding's keys for display, e.g. "q / Ctrl+C"
+func formatKeys(keys []string) string {
+	labels := make([]string, len(keys))
+	for i, k := range keys {
+		labels[i] = formatKey(k)
+	}
+	return strings.Join(labels, " / ")
+}
+
+func formatKey(k string) string {
+	switch k {
+	case " ":
+		return "Space"
+	case "up":
+		return "↑"
+	case "down":
+		return "↓"
+	case "left":
+		return "←"
+	case "right":
+		return "→"
+	case "esc":
+		return "Esc"
+	case "enter":
+		return "Enter"
+	case "tab":
+		return "Tab"
+	}
+	if rest, ok := strings.CutPrefix(k, "ctrl+"); ok {
+		return "Ctrl+" + strings.ToUpper(rest)
+	}
+	if rest, ok := strings.CutPrefix(k, "shift+"); ok {
+		return "Shift+" + formatKey(rest)
+	}
+	return k
+}
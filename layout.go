@@ -0,0 +1,116 @@
+package main
+
+import "github.com/charmbracelet/lipgloss"
+
+// panelID identifies a dashboard panel
+type panelID int
+
+const (
+	panelVitals panelID = iota
+	panelReactor
+	panelTelemetry
+	panelProcesses
+	panelNetwork
+	panelStorage
+	panelAlerts
+	panelServices
+	panelFleet
+	panelContainers
+	panelSensors
+	panelPower
+	panelMemory
+	panelConnections
+	panelPorts
+)
+
+// rect is a screen region in terminal cells
+type rect struct {
+	x, y, w, h int
+}
+
+func (r rect) contains(x, y int) bool {
+	return x >= r.x && x < r.x+r.w && y >= r.y && y < r.y+r.h
+}
+
+// panelRect places a panel on screen, including its border
+type panelRect struct {
+	rect
+	id panelID
+}
+
+// headerHeight is the number of rows above the panels: title and tab bar
+const headerHeight = 2
+
+// focusRing lists the current page's panels in Tab order
+func (m model) focusRing() []panelID {
+	var ids []panelID
+	for _, col := range m.currentPage().columns {
+		ids = append(ids, col...)
+	}
+	return ids
+}
+
+// panelLayout computes where each panel sits on screen. View renders from it
+// and mouse hit-testing reads it, so the two can never disagree.
+func (m model) panelLayout() []panelRect {
+	// Panels fill the rows below the header, leaving the last row free
+	areaHeight := m.height - headerHeight - 1
+
+	// A zoomed panel takes the whole area below the header
+	if m.zoomed {
+		return []panelRect{{
+			rect: rect{x: 0, y: headerHeight, w: m.width, h: areaHeight},
+			id:   m.focus,
+		}}
+	}
+
+	columns := m.currentPage().columns
+	colWidth := m.width / len(columns)
+
+	var rects []panelRect
+	for i, col := range columns {
+		rowHeight := areaHeight / len(col)
+		for j, id := range col {
+			h := rowHeight
+			if j == len(col)-1 {
+				h = areaHeight - rowHeight*j // Last panel takes the remainder
+			}
+			rects = append(rects, panelRect{
+				rect: rect{x: i * colWidth, y: headerHeight + j*rowHeight, w: colWidth, h: h},
+				id:   id,
+			})
+		}
+	}
+	return rects
+}
+
+// panelAt returns the panel under a screen cell
+func (m model) panelAt(x, y int) (panelRect, bool) {
+	for _, r := range m.panelLayout() {
+		if r.contains(x, y) {
+			return r, true
+		}
+	}
+	return panelRect{}, false
+}
+
+// alertRect locates the alert box inside the vitals panel, if one is showing
+func (m model) alertRect() (rect, bool) {
+	if !m.alertActive {
+		return rect{}, false
+	}
+	for _, r := range m.panelLayout() {
+		if r.id != panelVitals {
+			continue
+		}
+		// Border and padding are one cell each; the alert follows a two-line spacer
+		alert := m.renderAlert()
+		return rect{
+			x: r.x + 2,
+			y: r.y + 2 + lipgloss.Height(m.vitalsBody()) + 2,
+			w: lipgloss.Width(alert),
+			h: lipgloss.Height(alert),
+		}, true
+	}
+	return rect{}, false
+}
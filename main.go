@@ -1,20 +1,22 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"math"
 	"math/rand"
+	"os"
 	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/progress"
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
-	"github.com/shirou/gopsutil/v3/cpu"
-	"github.com/shirou/gopsutil/v3/mem"
-	"github.com/shirou/gopsutil/v3/net"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/charmbracelet/x/term"
 )
 
 // --- Styling Definitions ---
@@ -152,7 +154,21 @@ type logMsg string
 type model struct {
 	width, height int
 
+	// Environment (injectable for deterministic tests)
+	now    func() time.Time
+	rng    *rand.Rand
+	source MetricSource
+
+	exporter *exporter // nil unless --metrics-addr is set
+
+	// Fleet
+	fleet       *fleet     // nil unless agents are configured
+	remote      *agentConn // agent the dashboard is drilled into, nil for local
+	hosts       []hostStatus
+	fleetCursor int
+
 	// Components
+	keys     keyMap
 	spinner  spinner.Model
 	cpuBar   progress.Model
 	pwrBar   progress.Model
@@ -160,12 +176,21 @@ type model struct {
 	viewport viewport.Model
 
 	// Data
-	logs      []string
-	cpuVal    float64
-	pwrVal    float64
-	netVal    float64
-	booted    bool
-	resonance []float64
+	logs   []string
+	cpuVal float64
+	pwrVal float64 // memory, or battery charge when it drives THRUSTER POWER
+	netVal float64
+	memVal float64 // memory from the last sample, 0-1
+	booted bool
+
+	// Resonance strip: recent values of the bound metric, oldest first
+	resonance     []float64
+	resBinding    resonanceBinding
+	lastSample    Sample
+	lastSampleAt  time.Time
+	lastDiskIO    diskIOMsg
+	diskReadRate  float64 // bytes/s
+	diskWriteRate float64
 
 	// Matrix Data
 	matrixCols  int
@@ -174,6 +199,7 @@ type model struct {
 	matrixHeads []int
 	matrixTails []int
 	matrixSpeed []int
+	rain        *matrixRenderer
 
 	// HUD Features
 	currentMode     string
@@ -182,9 +208,10 @@ type model struct {
 	alertActive     bool
 	alertMessage    string
 	alertSeverity   int
+	alertHistory    []alertRecord
 	pulsePhase      float64
 	scanlinePos     int
-	targetAngles    []float64
+	radarSweep      float64 // bearing of the radar sweep, degrees
 	dataStreamChars []string
 	hologramChars   []string
 
@@ -194,7 +221,62 @@ type model struct {
 	currentTheme    int
 	showSoundWave   bool
 	audioLevels     []float64
+	audioPeaks      []float64 // peak-hold markers above the bars
+	peakHold        []int     // readings left before each peak starts to fall
+	audio           *audioSource
+	audioLive       bool // levels come from the audio source, not the RNG
+	audioErr        error
 	arcReactorPhase float64
+	showAlerts      bool
+	palette         paletteState
+	focus           panelID
+	zoomed          bool
+	page            int
+
+	// Pages
+	procCache  *processCache
+	processes  []processInfo
+	procErr    error
+	procTable  dataTable
+	disks      []diskInfo
+	diskTable  dataTable
+	lastIfaces ifacesMsg
+	ifaceTable dataTable
+	alertTable dataTable
+	widgets    []widget
+
+	docker         *dockerClient
+	containers     []containerInfo
+	containerErr   error
+	containerTable dataTable
+	logTail        *containerLogStream // container whose logs stream into telemetry
+
+	thermal     *thermalReader
+	temps       []tempReading
+	fans        []fanReading
+	thermalErr  error
+	sensorTable dataTable
+
+	supplies        *powerReader
+	power           powerMsg
+	thrusterBattery bool    // THRUSTER POWER shows battery charge instead of memory
+	lowBattery      float64 // percent
+	batteryAlert    int     // severity already raised for the current discharge
+
+	memReader *memoryReader
+	memory    memoryMsg
+
+	connReader *connectionReader
+	conns      []connInfo
+	connErr    error
+	connSeen   map[string]time.Time // first sighting of each open connection
+	blips      []radarBlip
+	connTable  dataTable
+
+	listeners      []listenInfo
+	portAllow      portAllowlist
+	exposedAlerted map[string]bool // flagged listeners already alerted on
+	portTable      dataTable
 
 	// Boot Sequence
 	bootPhase    int
@@ -219,6 +301,10 @@ func initialModel() model {
 	vp := viewport.New(40, 15) // Size updated on window resize
 
 	return model{
+		now:             time.Now,
+		rng:             rand.New(rand.NewSource(time.Now().UnixNano())),
+		source:          localSource{},
+		keys:            defaultKeyMap(),
 		spinner:         s,
 		cpuBar:          p1,
 		pwrBar:          p2,
@@ -227,10 +313,12 @@ func initialModel() model {
 		logs:            []string{"Initializing J.A.R.V.I.S. Protocol..."},
 		cpuVal:          0.2,
 		pwrVal:          0.8,
+		lowBattery:      defaultLowBattery,
 		netVal:          0.5,
-		resonance:       make([]float64, 20),
+		resBinding:      resonanceBinding{metric: "cpu"},
 		matrixCols:      0,
 		matrixRows:      0,
+		rain:            newMatrixRenderer(),
 		currentMode:     "FLIGHT",
 		tickCount:       0,
 		glitchActive:    false,
@@ -239,61 +327,250 @@ func initialModel() model {
 		alertSeverity:   0,
 		pulsePhase:      0,
 		scanlinePos:     0,
-		targetAngles:    []float64{0, 120, 240},
 		dataStreamChars: []string{"⬡", "⬢", "◈", "◇", "◆", "◊"},
 		hologramChars:   []string{"█", "▓", "▒", "░", "▄", "▀"},
 		paused:          false,
 		showHelp:        false,
 		currentTheme:    0,
 		showSoundWave:   true,
-		audioLevels:     make([]float64, 16),
+		audioLevels:     make([]float64, audioBands),
+		audioPeaks:      make([]float64, audioBands),
+		peakHold:        make([]int, audioBands),
 		arcReactorPhase: 0,
+		palette:         newPaletteState(),
+		focus:           panelTelemetry,
+		procCache:       newProcessCache(),
 		bootPhase:       0,
 		bootComplete:    false,
 		bootMessage:     "Initializing J.A.R.V.I.S. Protocol...",
 		systemScan:      false,
 		scanProgress:    0,
+		procTable: newDataTable(
+			tableColumn{title: "PID", width: 7, right: true},
+			tableColumn{title: "NAME"},
+			tableColumn{title: "CPU%", width: 6, right: true},
+			tableColumn{title: "MEM%", width: 6, right: true},
+			tableColumn{title: "RSS", width: 10, right: true},
+		),
+		diskTable: newDataTable(
+			tableColumn{title: "MOUNT"},
+			tableColumn{title: "FS", width: 8},
+			tableColumn{title: "SIZE", width: 10, right: true},
+			tableColumn{title: "USED", width: 10, right: true},
+			tableColumn{title: "FREE", width: 10, right: true},
+			tableColumn{title: "USAGE", width: 15},
+		),
+		ifaceTable: newDataTable(
+			tableColumn{title: "INTERFACE"},
+			tableColumn{title: "RX/s", width: 12, right: true},
+			tableColumn{title: "TX/s", width: 12, right: true},
+			tableColumn{title: "RX TOTAL", width: 10, right: true},
+			tableColumn{title: "TX TOTAL", width: 10, right: true},
+			tableColumn{title: "ERRORS", width: 6, right: true},
+		),
+		containerTable: newDataTable(
+			tableColumn{title: "NAME"},
+			tableColumn{title: "IMAGE", width: 20},
+			tableColumn{title: "STATUS", width: 18},
+			tableColumn{title: "CPU%", width: 6, right: true},
+			tableColumn{title: "MEM", width: 21, right: true},
+			tableColumn{title: "NET RX / TX", width: 21, right: true},
+		),
+		sensorTable: newDataTable(
+			tableColumn{title: "SENSOR"},
+			tableColumn{title: "CHIP", width: 12},
+			tableColumn{title: "READING", width: 10, right: true},
+			tableColumn{title: "HIGH", width: 7, right: true},
+			tableColumn{title: "CRIT", width: 7, right: true},
+		),
+		connTable: newDataTable(
+			tableColumn{title: "PROTO", width: 5},
+			tableColumn{title: "LOCAL", width: 21},
+			tableColumn{title: "REMOTE", width: 21},
+			tableColumn{title: "STATE", width: 11},
+			tableColumn{title: "PID", width: 7, right: true},
+			tableColumn{title: "PROCESS"},
+			tableColumn{title: "AGE", width: 4, right: true},
+		),
+		portTable: newDataTable(
+			tableColumn{title: "PROTO", width: 5},
+			tableColumn{title: "ADDRESS", width: 24},
+			tableColumn{title: "PORT", width: 5, right: true},
+			tableColumn{title: "PID", width: 7, right: true},
+			tableColumn{title: "PROCESS"},
+			tableColumn{title: "EXPOSURE", width: 9},
+		),
+		alertTable: newDataTable(
+			tableColumn{title: "TIME", width: 8},
+			tableColumn{title: "SEVERITY", width: 8},
+			tableColumn{title: "MESSAGE"},
+			tableColumn{title: "ACK", width: 3},
+		),
 	}
 }
 
 // katakana returns a random half-width katakana rune or a digit/latin char
-func randomMatrixChar() rune {
+func randomMatrixChar(rng *rand.Rand) rune {
 	// Half-width Katakana: FF66-FF9D
 	// Digits: 0030-0039
 	// Mix: 80% Katakana, 20% Digits/Latin
-	if rand.Float64() < 0.8 {
-		return rune(0xFF66 + rand.Intn(0xFF9D-0xFF66+1))
+	if rng.Float64() < 0.8 {
+		return rune(0xFF66 + rng.Intn(0xFF9D-0xFF66+1))
 	}
-	return rune('0' + rand.Intn(10))
+	return rune('0' + rng.Intn(10))
 }
 
-// updateSystemStats fetches real system statistics
-func (m *model) updateSystemStats() {
-	// CPU Usage
-	if percentages, err := cpu.Percent(0, false); err == nil && len(percentages) > 0 {
-		m.cpuVal = percentages[0] / 100.0
+// updateSystemStats applies a vitals sample from the metric source
+func (m *model) updateSystemStats(s Sample) {
+	m.cpuVal = s.CPUPercent / 100.0
+	m.memVal = s.MemUsedPercent / 100.0
+	if charge, ok := m.power.charge(); m.thrusterBattery && ok {
+		m.pwrVal = charge
+	} else {
+		m.pwrVal = s.MemUsedPercent / 100.0
 	}
 
-	// Memory Usage
-	if vmem, err := mem.VirtualMemory(); err == nil {
-		m.pwrVal = vmem.UsedPercent / 100.0
+	// Network I/O (simplified - just check if there's activity)
+	// Use bytes sent + received as a rough indicator
+	totalBytes := float64(s.NetBytesSent + s.NetBytesRecv)
+	// Normalize to 0-1 range (this is a simplified approach)
+	m.netVal = math.Min(1.0, math.Mod(totalBytes, 1000000)/1000000.0)
+}
+
+// --- Actions ---
+
+// addLog appends a line to the telemetry stream and keeps the viewport pinned to the bottom
+func (m *model) addLog(text string) {
+	m.logs = append(m.logs, logLabel.Render(">>")+" "+logText.Render(text))
+	if len(m.logs) > 50 {
+		m.logs = m.logs[1:] // Keep buffer small
 	}
+	m.viewport.SetContent(strings.Join(m.logs, "\n"))
+	m.viewport.GotoBottom()
+}
 
-	// Network I/O (simplified - just check if there's activity)
-	if netStats, err := net.IOCounters(false); err == nil && len(netStats) > 0 {
-		// Use bytes sent + received as a rough indicator
-		totalBytes := float64(netStats[0].BytesSent + netStats[0].BytesRecv)
-		// Normalize to 0-1 range (this is a simplified approach)
-		m.netVal = math.Min(1.0, math.Mod(totalBytes, 1000000)/1000000.0)
+func (m *model) setTheme(idx int) {
+	m.currentTheme = idx % len(themes)
+	m.addLog(fmt.Sprintf("Theme switched to: %s", m.getTheme().Name))
+}
+
+func (m *model) cycleTheme() {
+	m.setTheme(m.currentTheme + 1)
+}
+
+func (m *model) togglePause() {
+	m.paused = !m.paused
+	status := "PAUSED"
+	if !m.paused {
+		status = "RESUMED"
+	}
+	m.addLog(fmt.Sprintf("System %s", status))
+}
+
+func (m *model) toggleSoundWave() {
+	m.showSoundWave = !m.showSoundWave
+	status := "ENABLED"
+	if !m.showSoundWave {
+		status = "DISABLED"
+	}
+	m.addLog(fmt.Sprintf("Sound visualization %s", status))
+}
+
+func (m *model) reboot() {
+	m.tickCount = 0
+	m.pulsePhase = 0
+	m.arcReactorPhase = 0
+	m.addLog("System reboot initiated")
+}
+
+// startScan begins a manual scan. It refreshes the socket list so the port
+// audit at the end of the scan is current.
+func (m *model) startScan() tea.Cmd {
+	m.systemScan = true
+	m.scanProgress = 0
+	m.addLog("Manual system scan initiated")
+	if m.connReader != nil && m.localHost() {
+		return collectConnectionsCommand(m.connReader)
+	}
+	return nil
+}
+
+// resize fits every component to the panel slot the layout gives it.
+// It runs on window resize and whenever the layout changes (e.g. zoom).
+func (m *model) resize() {
+	for _, r := range m.panelLayout() {
+		// Border and padding take two cells on each axis
+		colWidth := r.w - 4
+
+		switch r.id {
+		case panelVitals:
+			m.cpuBar.Width = colWidth - 10
+			m.pwrBar.Width = colWidth - 10
+			m.netBar.Width = colWidth - 10
+
+		case panelReactor:
+			// Leave room for Arc Reactor (Spinner + Text) + Borders
+			m.resizeMatrix(colWidth-2, r.h-12)
+
+		case panelTelemetry:
+			m.viewport.Width = colWidth
+			m.viewport.Height = r.h - 8
+		}
+
+		// Table panels: border, padding, panel header and table header
+		if t := m.tableFor(r.id); t != nil {
+			t.setHeight(r.h - tableTop - 3)
+		}
+	}
+}
+
+// resizeMatrix makes the rain grid cover cols x rows, keeping existing columns where possible
+func (m *model) resizeMatrix(cols, rows int) {
+	m.matrixCols = max(cols, 0)
+	m.matrixRows = max(rows, 0)
+
+	if len(m.matrixHeads) != m.matrixCols {
+		// Re-initialize if width changed
+		m.matrixGrid = make([][]rune, m.matrixCols)
+		m.matrixHeads = make([]int, m.matrixCols)
+		m.matrixTails = make([]int, m.matrixCols)
+		m.matrixSpeed = make([]int, m.matrixCols)
+
+		for x := 0; x < m.matrixCols; x++ {
+			m.matrixGrid[x] = make([]rune, m.matrixRows)
+			// Randomize start pos to be scattered off-screen or mid-screen
+			m.matrixHeads[x] = m.rng.Intn(m.matrixRows*2+1) - m.matrixRows
+			m.matrixTails[x] = m.rng.Intn(10) + 5
+			m.matrixSpeed[x] = m.rng.Intn(3) + 1 // speed 1 to 3
+
+			// Fill grid with random chars initially
+			for y := 0; y < m.matrixRows; y++ {
+				m.matrixGrid[x][y] = randomMatrixChar(m.rng)
+			}
+		}
+	} else if m.matrixCols > 0 && len(m.matrixGrid[0]) != m.matrixRows {
+		// Height changed, resize columns
+		for x := 0; x < m.matrixCols; x++ {
+			newCol := make([]rune, m.matrixRows)
+			copy(newCol, m.matrixGrid[x])
+			// Fill new space
+			for y := len(m.matrixGrid[x]); y < m.matrixRows; y++ {
+				newCol[y] = randomMatrixChar(m.rng)
+			}
+			m.matrixGrid[x] = newCol
+		}
 	}
 }
 
 func (m model) Init() tea.Cmd {
-	return tea.Batch(
+	cmds := []tea.Cmd{
 		m.spinner.Tick,
 		tickCommand(),
-		generateLogCommand(),
-	)
+		generateLogCommand(m.rng),
+	}
+	cmds = append(cmds, m.backgroundCollectCommands()...)
+	cmds = append(cmds, m.resonanceHistoryCommand())
+	return tea.Batch(append(cmds, m.widgetCommands()...)...)
 }
 
 // --- Logic ---
@@ -305,132 +582,194 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "q", "ctrl+c":
+		if m.palette.active {
+			return m.updatePalette(msg)
+		}
+
+		switch {
+		case key.Matches(msg, m.keys.Quit):
 			return m, tea.Quit
 
-		case "h", "?":
+		case key.Matches(msg, m.keys.Palette):
+			cmd = m.openPalette()
+			return m, cmd
+
+		case key.Matches(msg, m.keys.Help):
 			m.showHelp = !m.showHelp
 
-		case "t":
-			m.currentTheme = (m.currentTheme + 1) % len(themes)
-			theme := m.getTheme()
-			newLog := fmt.Sprintf("Theme switched to: %s", theme.Name)
-			m.logs = append(m.logs, logLabel.Render(">>")+" "+logText.Render(newLog))
-			if len(m.logs) > 50 {
-				m.logs = m.logs[1:]
-			}
-			m.viewport.SetContent(strings.Join(m.logs, "\n"))
-			m.viewport.GotoBottom()
-
-		case "p":
-			m.paused = !m.paused
-			status := "PAUSED"
-			if !m.paused {
-				status = "RESUMED"
-			}
-			newLog := fmt.Sprintf("System %s", status)
-			m.logs = append(m.logs, logLabel.Render(">>")+" "+logText.Render(newLog))
-			if len(m.logs) > 50 {
-				m.logs = m.logs[1:]
-			}
-			m.viewport.SetContent(strings.Join(m.logs, "\n"))
-			m.viewport.GotoBottom()
-
-		case "s":
-			m.showSoundWave = !m.showSoundWave
-			status := "ENABLED"
-			if !m.showSoundWave {
-				status = "DISABLED"
-			}
-			newLog := fmt.Sprintf("Sound visualization %s", status)
-			m.logs = append(m.logs, logLabel.Render(">>")+" "+logText.Render(newLog))
-			if len(m.logs) > 50 {
-				m.logs = m.logs[1:]
-			}
-			m.viewport.SetContent(strings.Join(m.logs, "\n"))
-			m.viewport.GotoBottom()
-
-		case "r":
-			m.tickCount = 0
-			m.pulsePhase = 0
-			m.arcReactorPhase = 0
-			newLog := "System reboot initiated"
-			m.logs = append(m.logs, logLabel.Render(">>")+" "+logText.Render(newLog))
-			if len(m.logs) > 50 {
-				m.logs = m.logs[1:]
+		case key.Matches(msg, m.keys.Theme):
+			m.cycleTheme()
+
+		case key.Matches(msg, m.keys.Pause):
+			m.togglePause()
+
+		case key.Matches(msg, m.keys.SoundWave):
+			m.toggleSoundWave()
+
+		case key.Matches(msg, m.keys.Reboot):
+			m.reboot()
+
+		case key.Matches(msg, m.keys.Scan):
+			cmds = append(cmds, m.startScan())
+
+		case key.Matches(msg, m.keys.Alerts):
+			m.showAlerts = !m.showAlerts
+
+		case key.Matches(msg, m.keys.Close):
+			m.showAlerts = false
+			m.showHelp = false
+
+		case key.Matches(msg, m.keys.Zoom):
+			m.toggleZoom()
+
+		case key.Matches(msg, m.keys.Open):
+			if m.focus == panelFleet {
+				cmds = append(cmds, m.openHost(m.fleetCursor))
 			}
-			m.viewport.SetContent(strings.Join(m.logs, "\n"))
-			m.viewport.GotoBottom()
-
-		case " ":
-			newLog := "Manual system scan initiated"
-			m.logs = append(m.logs, logLabel.Render(">>")+" "+logText.Render(newLog))
-			if len(m.logs) > 50 {
-				m.logs = m.logs[1:]
+
+		case m.focus == panelContainers && key.Matches(msg, m.keys.Start):
+			cmds = append(cmds, m.containerAction("start"))
+
+		case m.focus == panelContainers && key.Matches(msg, m.keys.Stop):
+			cmds = append(cmds, m.containerAction("stop"))
+
+		case m.focus == panelContainers && key.Matches(msg, m.keys.Restart):
+			cmds = append(cmds, m.containerAction("restart"))
+
+		case m.focus == panelContainers && key.Matches(msg, m.keys.Logs):
+			cmds = append(cmds, m.toggleContainerLogs())
+
+		case key.Matches(msg, m.keys.Pages...):
+			for i, b := range m.keys.Pages {
+				if key.Matches(msg, b) {
+					m.setPage(i)
+				}
 			}
-			m.viewport.SetContent(strings.Join(m.logs, "\n"))
-			m.viewport.GotoBottom()
 
-		case "up":
-			m.viewport.LineUp(1)
+		case key.Matches(msg, m.keys.FocusNext):
+			m.cycleFocus(1)
+
+		case key.Matches(msg, m.keys.FocusPrev):
+			m.cycleFocus(-1)
 
-		case "down":
-			m.viewport.LineDown(1)
+		case key.Matches(msg, m.keys.ScrollUp):
+			m.scrollPanel(m.focus, -1)
+
+		case key.Matches(msg, m.keys.ScrollDown):
+			m.scrollPanel(m.focus, 1)
 		}
 
-	case tea.WindowSizeMsg:
-		m.width = msg.Width
-		m.height = msg.Height
+	case tea.MouseMsg:
+		return m.updateMouse(msg)
 
-		// Responsive resizing
-		colWidth := (m.width / 3) - 4
-		m.cpuBar.Width = colWidth - 10
-		m.pwrBar.Width = colWidth - 10
-		m.netBar.Width = colWidth - 10
-		m.viewport.Width = colWidth
-		m.viewport.Height = m.height - 10
-
-		// Resize Matrix
-		// Ensure we have a grid that covers the center panel
-		m.matrixCols = colWidth - 2
-		m.matrixRows = m.height - 14 // Leave room for Arc Reactor (Spinner + Text) + Borders
-		if m.matrixRows < 0 {
-			m.matrixRows = 0
+	// The exporter describes this machine, so it skips readings taken
+	// while drilled into a fleet host or from a remote source
+	case sampleMsg:
+		if msg.err == nil {
+			m.updateSystemStats(msg.sample)
+			m.pushResonance(msg.sample)
+		}
+		if m.localVitals() {
+			m.exporter.setSample(msg.sample, msg.err, m.now())
 		}
 
-		if len(m.matrixHeads) != m.matrixCols {
-			// Re-initialize if width changed
-			m.matrixGrid = make([][]rune, m.matrixCols)
-			m.matrixHeads = make([]int, m.matrixCols)
-			m.matrixTails = make([]int, m.matrixCols)
-			m.matrixSpeed = make([]int, m.matrixCols)
-
-			for x := 0; x < m.matrixCols; x++ {
-				m.matrixGrid[x] = make([]rune, m.matrixRows)
-				// Randomize start pos to be scattered off-screen or mid-screen
-				m.matrixHeads[x] = rand.Intn(m.matrixRows*2) - m.matrixRows
-				m.matrixTails[x] = rand.Intn(10) + 5
-				m.matrixSpeed[x] = rand.Intn(3) + 1 // speed 1 to 3
-
-				// Fill grid with random chars initially
-				for y := 0; y < m.matrixRows; y++ {
-					m.matrixGrid[x][y] = randomMatrixChar()
-				}
-			}
-		} else if len(m.matrixGrid[0]) != m.matrixRows {
-			// Height changed, resize columns
-			for x := 0; x < m.matrixCols; x++ {
-				newCol := make([]rune, m.matrixRows)
-				copy(newCol, m.matrixGrid[x])
-				// Fill new space
-				for y := len(m.matrixGrid[x]); y < m.matrixRows; y++ {
-					newCol[y] = randomMatrixChar()
-				}
-				m.matrixGrid[x] = newCol
-			}
+	case processesMsg:
+		// A failed read keeps the last table, with the error in the title
+		m.procErr = msg.err
+		if msg.err == nil {
+			m.setProcesses(msg.procs)
+		}
+		if m.localHost() {
+			m.exporter.setProcesses(msg.err, m.now())
+		}
+
+	case disksMsg:
+		m.setDisks(msg)
+		if m.localHost() {
+			m.exporter.setDisks(msg, m.now())
+		}
+
+	case ifacesMsg:
+		m.setIfaces(msg)
+		if m.localHost() {
+			m.exporter.setIfaces(msg.stats, m.now())
+		}
+
+	case resonanceHistoryMsg:
+		m.backfillResonance(msg)
+
+	case fleetMsg:
+		m.hosts = msg
+		m.moveFleetCursor(0)
+
+	case containersMsg:
+		m.setContainers(msg)
+
+	case containerActionMsg:
+		if msg.err != nil {
+			m.addLog(fmt.Sprintf("Container %s: %s failed: %v", msg.name, msg.action, msg.err))
+		} else {
+			m.addLog(fmt.Sprintf("Container %s: %s complete", msg.name, msg.action))
+		}
+		if m.docker != nil {
+			cmds = append(cmds, collectContainersCommand(m.docker))
+		}
+
+	case containerLogStartedMsg:
+		if msg.err != nil {
+			m.addLog(fmt.Sprintf("Cannot follow logs: %v", msg.err))
+			break
+		}
+		m.stopContainerLogs()
+		m.logTail = msg.stream
+		cmds = append(cmds, readContainerLogCommand(msg.stream))
+
+	case containerLogMsg:
+		if msg.stream != m.logTail {
+			// A line from a stream we have since stopped following
+			msg.stream.Close()
+			break
+		}
+		if msg.err != nil {
+			m.addLog(fmt.Sprintf("Log stream of %s ended", msg.stream.name))
+			m.logTail = nil
+			break
+		}
+		m.addLog("[" + msg.stream.name + "] " + ansi.Strip(msg.line))
+		cmds = append(cmds, readContainerLogCommand(msg.stream))
+
+	case thermalMsg:
+		m.setThermal(msg)
+
+	case powerMsg:
+		m.setPower(msg)
+
+	case memoryMsg:
+		m.memory = msg
+
+	case connectionsMsg:
+		m.setConnections(msg)
+
+	case audioMsg:
+		m.setAudio(msg)
+
+	case diskIOMsg:
+		m.setDiskIO(msg)
+
+	case widgetPollMsg:
+		if msg.idx >= 0 && msg.idx < len(m.widgets) {
+			cmds = append(cmds, pollWidgetCommand(msg.idx, m.widgets[msg.idx]))
 		}
 
+	case widgetResultMsg:
+		cmds = append(cmds, m.setWidgetResult(msg))
+
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+
+		m.resize()
+
 	case tickMsg:
 		// Skip updates if paused
 		if m.paused {
@@ -440,10 +779,10 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		// Update real system stats every 10 ticks (~2 seconds)
 		if m.tickCount%10 == 0 {
-			m.updateSystemStats()
+			cmds = append(cmds, m.backgroundCollectCommands()...)
 		} else {
 			// Add small random variations between updates for smooth animation
-			m.cpuVal += (rand.Float64() - 0.5) * 0.02
+			m.cpuVal += (m.rng.Float64() - 0.5) * 0.02
 			if m.cpuVal > 1 {
 				m.cpuVal = 1
 			}
@@ -451,7 +790,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.cpuVal = 0
 			}
 
-			m.pwrVal += (rand.Float64() - 0.5) * 0.01
+			m.pwrVal += (m.rng.Float64() - 0.5) * 0.01
 			if m.pwrVal > 1 {
 				m.pwrVal = 1
 			}
@@ -459,7 +798,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.pwrVal = 0
 			}
 
-			m.netVal += (rand.Float64() - 0.5) * 0.02
+			m.netVal += (m.rng.Float64() - 0.5) * 0.02
 			if m.netVal > 1 {
 				m.netVal = 1
 			}
@@ -468,13 +807,6 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
-		// Update Resonance
-		// Shift left
-		if len(m.resonance) > 0 {
-			m.resonance = m.resonance[1:]
-			m.resonance = append(m.resonance, rand.Float64())
-		}
-
 		// Update Matrix
 		// 1. Move Heads
 		for x := 0; x < m.matrixCols; x++ {
@@ -485,15 +817,15 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Or move by speed? Speed might be too fast.
 			// Let's use a probability based on speed to simulate variable update rates per column
 			// Speed 1: 33% move, Speed 2: 66% move, Speed 3: 100% move
-			if rand.Intn(4) < m.matrixSpeed[x] {
+			if m.rng.Intn(4) < m.matrixSpeed[x] {
 				m.matrixHeads[x]++
 			}
 
 			// Reset if trail is off bottom
 			if m.matrixHeads[x]-m.matrixTails[x] > m.matrixRows {
-				m.matrixHeads[x] = 0 - rand.Intn(10)
-				m.matrixTails[x] = rand.Intn(15) + 5
-				m.matrixSpeed[x] = rand.Intn(3) + 1
+				m.matrixHeads[x] = 0 - m.rng.Intn(10)
+				m.matrixTails[x] = m.rng.Intn(15) + 5
+				m.matrixSpeed[x] = m.rng.Intn(3) + 1
 			}
 		}
 
@@ -501,9 +833,9 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Change ~1% of visible characters per tick
 		glitchCount := (m.matrixCols * m.matrixRows) / 100
 		for i := 0; i < glitchCount; i++ {
-			gx := rand.Intn(m.matrixCols)
-			gy := rand.Intn(m.matrixRows)
-			m.matrixGrid[gx][gy] = randomMatrixChar()
+			gx := m.rng.Intn(m.matrixCols)
+			gy := m.rng.Intn(m.matrixRows)
+			m.matrixGrid[gx][gy] = randomMatrixChar(m.rng)
 		}
 
 		// HUD Updates
@@ -511,21 +843,23 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.pulsePhase += 0.15
 		m.scanlinePos = (m.scanlinePos + 1) % 10
 
-		// Update target angles for radar effect
-		for i := range m.targetAngles {
-			m.targetAngles[i] += 3
-			if m.targetAngles[i] >= 360 {
-				m.targetAngles[i] = 0
-			}
-		}
+		// Turn the radar sweep
+		m.radarSweep = math.Mod(m.radarSweep+radarSweepStep, 360)
 
-		// Update audio levels for sound wave visualization
-		for i := range m.audioLevels {
-			m.audioLevels[i] = rand.Float64()
+		// Update audio levels for sound wave visualization; with an audio
+		// source they follow its spectrum instead
+		if m.audio != nil {
+			cmds = append(cmds, readAudioCommand(m.audio))
+		}
+		if !m.audioLive {
+			for i := range m.audioLevels {
+				m.audioLevels[i] = m.rng.Float64()
+			}
 		}
 
-		// Update Arc Reactor phase for pulsing animation
-		m.arcReactorPhase += 0.1
+		// Update Arc Reactor phase for pulsing animation; it beats up to
+		// three times faster as the hottest sensor nears critical
+		m.arcReactorPhase += 0.1 * (1 + 2*m.reactorHeat())
 
 		// Periodic glitch effect (every 50 ticks)
 		if m.tickCount%50 == 0 {
@@ -535,11 +869,11 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Random mode switching
 		if m.tickCount%200 == 0 {
 			modes := []string{"FLIGHT", "COMBAT", "STEALTH", "ANALYSIS", "NAVIGATION"}
-			m.currentMode = modes[rand.Intn(len(modes))]
+			m.currentMode = modes[m.rng.Intn(len(modes))]
 		}
 
 		// Random alert generation (rare)
-		if rand.Float64() < 0.005 {
+		if m.rng.Float64() < 0.005 {
 			alerts := []string{
 				"DETECTING HOSTILES",
 				"ENERGY SPIKE",
@@ -547,28 +881,31 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				"TARGET LOCKED",
 				"SYSTEM WARNING",
 			}
-			m.alertActive = true
-			m.alertMessage = alerts[rand.Intn(len(alerts))]
-			m.alertSeverity = rand.Intn(3) + 1
+			m.raiseAlert(alerts[m.rng.Intn(len(alerts))], m.rng.Intn(3)+1)
 		}
 
 		// Clear alert after 5 seconds (~25 ticks at 200ms)
 		if m.alertActive && m.tickCount%25 == 0 {
 			m.alertActive = false
+			m.publishAlerts()
+		}
+
+		// Advance manual system scan
+		if m.systemScan {
+			m.scanProgress += 0.05
+			if m.scanProgress >= 1 {
+				m.systemScan = false
+				m.scanProgress = 0
+				m.auditPorts()
+			}
 		}
 
 		cmds = append(cmds, tickCommand())
 
 	case logMsg:
 		// Add new log entry
-		newLog := fmt.Sprintf("%s %s", logLabel.Render(">>"), logText.Render(string(msg)))
-		m.logs = append(m.logs, newLog)
-		if len(m.logs) > 50 {
-			m.logs = m.logs[1:] // Keep buffer small
-		}
-		m.viewport.SetContent(strings.Join(m.logs, "\n"))
-		m.viewport.GotoBottom()
-		cmds = append(cmds, generateLogCommand())
+		m.addLog(string(msg))
+		cmds = append(cmds, generateLogCommand(m.rng))
 
 	case spinner.TickMsg:
 		m.spinner, cmd = m.spinner.Update(msg)
@@ -602,12 +939,107 @@ func (m model) View() string {
 		return "Calibrating Suits..."
 	}
 
-	// Calculate panel sizes based on window width
-	panelWidth := (m.width / 3) - 2
-	panelHeight := m.height - 4
+	// Render each panel into the slot the layout assigns it, stacking the
+	// panels that share a column
+	var columns [][]string
+	lastX := -1
+	for _, r := range m.panelLayout() {
+		if r.x != lastX {
+			columns = append(columns, nil)
+			lastX = r.x
+		}
+		columns[len(columns)-1] = append(columns[len(columns)-1], m.renderPanel(r.id, r.w-2, r.h-2))
+	}
+
+	// Combine Columns
+	panels := make([]string, len(columns))
+	for i, col := range columns {
+		panels[i] = lipgloss.JoinVertical(lipgloss.Left, col...)
+	}
+	ui := lipgloss.JoinHorizontal(lipgloss.Top, panels...)
+
+	theme := m.getTheme()
+
+	// Add Master Header with theme, naming the fleet host when drilled in
+	heading := "/// STARK INDUSTRIES INTERFACE - " + theme.Name
+	if host := m.hostName(); host != "" {
+		heading += " - HOST " + strings.ToUpper(host)
+	}
+	title := lipgloss.NewStyle().
+		Width(m.width).
+		Align(lipgloss.Center).
+		Foreground(theme.Primary).
+		Background(theme.Background).
+		Render(heading + " ///")
+
+	baseView := lipgloss.JoinVertical(lipgloss.Top, title, m.renderTabBar(), ui)
+
+	// Command palette takes precedence over other overlays
+	if m.palette.active {
+		return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, m.renderPalette())
+	}
+
+	if m.showAlerts {
+		return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, m.renderAlertHistory())
+	}
+
+	// Show help menu overlay if active
+	if m.showHelp {
+		helpMenu := m.renderHelpMenu()
+		// Center the help menu
+		helpOverlay := lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, helpMenu)
+		return helpOverlay
+	}
 
-	// --- LEFT PANEL: VITALS ---
-	vitalsContent := lipgloss.JoinVertical(lipgloss.Left,
+	return baseView
+}
+
+// --- Panels ---
+
+// renderPanel draws a single panel at the given inner size (excluding borders)
+func (m model) renderPanel(id panelID, width, height int) string {
+	switch id {
+	case panelVitals:
+		return m.renderVitalsPanel(width, height)
+	case panelReactor:
+		return m.renderReactorPanel(width, height)
+	case panelTelemetry:
+		return m.renderTelemetryPanel(width, height)
+	case panelProcesses:
+		title := "PROCESS MONITOR"
+		if m.procErr != nil {
+			title += " · " + m.procErr.Error()
+		}
+		return m.renderTablePanel(id, title, m.procTable, width, height)
+	case panelNetwork:
+		return m.renderTablePanel(id, "NETWORK INTERFACES", m.ifaceTable, width, height)
+	case panelStorage:
+		return m.renderTablePanel(id, "STORAGE ARRAY", m.diskTable, width, height)
+	case panelAlerts:
+		return m.renderTablePanel(id, "ALERT LOG", m.alertTable, width, height)
+	case panelServices:
+		return m.renderWidgetsPanel(width, height)
+	case panelFleet:
+		return m.renderFleetPanel(width, height)
+	case panelContainers:
+		return m.renderContainersPanel(width, height)
+	case panelSensors:
+		return m.renderSensorsPanel(width, height)
+	case panelPower:
+		return m.renderPowerPanel(width, height)
+	case panelMemory:
+		return m.renderMemoryPanel(width, height)
+	case panelConnections:
+		return m.renderConnectionsPanel(width, height)
+	case panelPorts:
+		return m.renderPortsPanel(width, height)
+	}
+	return ""
+}
+
+// vitalsBody is the vitals panel content without the alert box
+func (m model) vitalsBody() string {
+	return lipgloss.JoinVertical(lipgloss.Left,
 		headerStyle.Render("SYSTEM VITALS"),
 		"",
 		m.renderClockHUD(),
@@ -627,40 +1059,31 @@ func (m model) View() string {
 		"\n",
 		lipgloss.NewStyle().Foreground(cDim).Render("Mark LXXXV // Online"),
 	)
+}
+
+func (m model) renderVitalsPanel(width, height int) string {
+	vitalsContent := m.vitalsBody()
 
 	if m.alertActive {
 		vitalsContent = lipgloss.JoinVertical(lipgloss.Left, vitalsContent, "\n", m.renderAlert())
 	}
 
-	leftPanel := boxStyle.Width(panelWidth).Height(panelHeight).Render(vitalsContent)
+	return m.panelStyle(panelVitals).Width(width).Height(height).Render(vitalsContent)
+}
 
-	// --- CENTER PANEL: ARC REACTOR ---
+func (m model) renderReactorPanel(width, height int) string {
 	theme := m.getTheme()
 
-	// Visualizer rendering
-	var resonanceView string
-	bars := []string{" ", "▂", "▃", "▄", "▅", "▆", "▇", "█"}
-	for _, v := range m.resonance {
-		idx := int(v * float64(len(bars)-1))
-		if idx < 0 {
-			idx = 0
-		}
-		if idx >= len(bars) {
-			idx = len(bars) - 1
-		}
-		resonanceView += bars[idx]
-	}
-
 	centerTop := lipgloss.JoinHorizontal(lipgloss.Top,
-		lipgloss.NewStyle().Width(panelWidth/2).Align(lipgloss.Center, lipgloss.Center).Render(
+		lipgloss.NewStyle().Width(width/2).Align(lipgloss.Center, lipgloss.Center).Render(
 			lipgloss.JoinVertical(lipgloss.Center,
 				m.renderEnhancedArcReactor(),
 				"\n",
 				lipgloss.NewStyle().Bold(true).Foreground(theme.Primary).Render("ARC REACTOR"),
-				lipgloss.NewStyle().Foreground(theme.Dim).Render("Output: 4.8 GJ/s"),
+				m.reactorReadout(),
 			),
 		),
-		lipgloss.NewStyle().Width(panelWidth/2).Align(lipgloss.Center, lipgloss.Center).Render(
+		lipgloss.NewStyle().Width(width/2).Align(lipgloss.Center, lipgloss.Center).Render(
 			lipgloss.JoinVertical(lipgloss.Center,
 				lipgloss.NewStyle().Foreground(theme.Accent).Bold(true).Render("TARGETING"),
 				"\n",
@@ -671,6 +1094,7 @@ func (m model) View() string {
 
 	centerContent := lipgloss.JoinVertical(lipgloss.Left,
 		centerTop,
+		lipgloss.NewStyle().Width(width).Align(lipgloss.Center).Render(m.renderResonance()),
 		"\n",
 		m.renderSoundWave(),
 		"\n",
@@ -685,11 +1109,12 @@ func (m model) View() string {
 		centerContent = glitchStyle.Render(centerContent)
 	}
 
-	centerPanel := boxStyle.Width(panelWidth).Height(panelHeight).
+	return m.panelStyle(panelReactor).Width(width).Height(height).
 		Align(lipgloss.Center, lipgloss.Center).
 		Render(centerContent)
+}
 
-	// --- RIGHT PANEL: LOGS ---
+func (m model) renderTelemetryPanel(width, height int) string {
 	logHeader := headerStyle.Render("TELEMETRY STREAM")
 	rightContent := lipgloss.JoinVertical(lipgloss.Left,
 		logHeader,
@@ -698,37 +1123,14 @@ func (m model) View() string {
 		lipgloss.NewStyle().Foreground(gridColor).Faint(true).Bold(true).Render("HOLOGRAPHIC FEED"),
 		m.renderHologramGrid(4),
 	)
-	rightPanel := boxStyle.Width(panelWidth).Height(panelHeight).
+	return m.panelStyle(panelTelemetry).Width(width).Height(height).
 		Render(rightContent)
-
-	// Combine Columns
-	ui := lipgloss.JoinHorizontal(lipgloss.Top, leftPanel, centerPanel, rightPanel)
-
-	// Add Master Header with theme
-	title := lipgloss.NewStyle().
-		Width(m.width).
-		Align(lipgloss.Center).
-		Foreground(theme.Primary).
-		Background(theme.Background).
-		Render("/// STARK INDUSTRIES INTERFACE - " + theme.Name + " ///")
-
-	baseView := lipgloss.JoinVertical(lipgloss.Top, title, ui)
-
-	// Show help menu overlay if active
-	if m.showHelp {
-		helpMenu := m.renderHelpMenu()
-		// Center the help menu
-		helpOverlay := lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, helpMenu)
-		return helpOverlay
-	}
-
-	return baseView
 }
 
 // --- HUD Helper Functions ---
 
 func (m model) renderClockHUD() string {
-	t := time.Now()
+	t := m.now()
 	timeStr := t.Format("15:04:05")
 	dateStr := t.Format("Jan 02 2006")
 
@@ -796,32 +1198,14 @@ func (m model) renderCircularGauge(value float64, size int, label string) string
 	)
 }
 
-func (m model) renderRadar() string {
-	blipChar := "●"
-
-	radarStr := lipgloss.NewStyle().Foreground(alertGreen).Bold(true).Render(
-		"     ◉\n   ╱ | ╲\n  " + blipChar + "--R--" + blipChar + "\n   ╲ | ╱\n     ◉",
-	)
-	return radarStr
-}
-
 func (m model) renderAlert() string {
 	if !m.alertActive {
 		return ""
 	}
 
-	severity := ""
+	severity := severityName(m.alertSeverity)
 	alertColor := alertStyle
 
-	switch m.alertSeverity {
-	case 1:
-		severity = "WARNING"
-	case 2:
-		severity = "CAUTION"
-	case 3:
-		severity = "CRITICAL"
-	}
-
 	if m.tickCount%10 < 5 {
 		alertColor = alertStyle.Background(lipgloss.Color("#660000"))
 	}
@@ -911,22 +1295,24 @@ func (m model) renderHelpMenu() string {
 	descStyle := lipgloss.NewStyle().
 		Foreground(theme.Dim)
 
-	content := lipgloss.JoinVertical(lipgloss.Left,
+	lines := []string{
 		titleStyle.Render("╔═══════════════════════════════════╗"),
 		titleStyle.Render("║    J.A.R.V.I.S. CONTROLS HELP    ║"),
 		titleStyle.Render("╚═══════════════════════════════════╝"),
 		"",
-		keyStyle.Render("  q / Ctrl+C")+" "+descStyle.Render("│ Exit Application"),
-		keyStyle.Render("  h / ?      ")+" "+descStyle.Render("│ Toggle This Help"),
-		keyStyle.Render("  t          ")+" "+descStyle.Render("│ Cycle Themes"),
-		keyStyle.Render("  p          ")+" "+descStyle.Render("│ Pause/Resume"),
-		keyStyle.Render("  s          ")+" "+descStyle.Render("│ Toggle Sound Wave"),
-		keyStyle.Render("  r          ")+" "+descStyle.Render("│ Reboot System"),
-		keyStyle.Render("  Space      ")+" "+descStyle.Render("│ Manual Scan"),
-		keyStyle.Render("  ↑ / ↓      ")+" "+descStyle.Render("│ Scroll Logs"),
-		"",
-		titleStyle.Render("Current Theme: "+theme.Name),
-	)
+	}
+
+	// Generated from the live keymap so overrides are always reflected
+	for _, nb := range m.keys.bindings() {
+		if !nb.binding.Enabled() {
+			continue
+		}
+		h := nb.binding.Help()
+		lines = append(lines, keyStyle.Render(fmt.Sprintf("  %-11s", h.Key))+" "+descStyle.Render("│ "+h.Desc))
+	}
+
+	lines = append(lines, "", titleStyle.Render("Current Theme: "+theme.Name))
+	content := lipgloss.JoinVertical(lipgloss.Left, lines...)
 
 	return helpStyle.Render(content)
 }
@@ -955,15 +1341,33 @@ func (m model) renderSoundWave() string {
 
 	waveStr := sb.String()
 
-	header := lipgloss.NewStyle().Foreground(theme.Accent).Bold(true).Render("AUDIO ANALYSIS")
+	title := "AUDIO ANALYSIS"
+	if m.audioErr != nil {
+		title += " · NO SIGNAL"
+	}
+	header := lipgloss.NewStyle().Foreground(theme.Accent).Bold(true).Render(title)
 	wave := lipgloss.NewStyle().Foreground(theme.Primary).Render(waveStr)
 	labels := lipgloss.NewStyle().Foreground(theme.Dim).Faint(true).Render("Bass  Mid  High")
+	if !m.audioLive {
+		return lipgloss.JoinVertical(lipgloss.Left, header, wave, labels)
+	}
 
-	return lipgloss.JoinVertical(lipgloss.Left, header, wave, labels)
+	// Peak markers sit above bars that have fallen at least a step below them
+	var peaks strings.Builder
+	for i, peak := range m.audioPeaks {
+		if int(peak*float64(len(bars)-1)) > int(m.audioLevels[i]*float64(len(bars)-1)) {
+			peaks.WriteString("▔")
+		} else {
+			peaks.WriteString(" ")
+		}
+	}
+	peakRow := lipgloss.NewStyle().Foreground(theme.Accent).Render(peaks.String())
+	return lipgloss.JoinVertical(lipgloss.Left, header, peakRow, wave, labels)
 }
 
 func (m model) renderEnhancedArcReactor() string {
 	theme := m.getTheme()
+	primary, accent := m.reactorColors()
 
 	// Pulsing effect using sine wave
 	pulseIntensity := (math.Sin(m.arcReactorPhase) + 1) / 2
@@ -972,21 +1376,21 @@ func (m model) renderEnhancedArcReactor() string {
 	var reactor strings.Builder
 
 	if pulseIntensity > 0.7 {
-		reactor.WriteString(lipgloss.NewStyle().Foreground(theme.Accent).Bold(true).Render("       ╔═══════╗\n"))
-		reactor.WriteString(lipgloss.NewStyle().Foreground(theme.Primary).Bold(true).Render("     ╔═╝ ◉ ◉ ◉ ╚═╗\n"))
-		reactor.WriteString(lipgloss.NewStyle().Foreground(theme.Primary).Render("    ║ ◉ ▓▓▓▓▓▓▓ ◉ ║\n"))
-		reactor.WriteString(lipgloss.NewStyle().Foreground(theme.Accent).Bold(true).Render("    ║ ◉ ▓█████▓ ◉ ║\n"))
-		reactor.WriteString(lipgloss.NewStyle().Foreground(theme.Primary).Render("    ║ ◉ ▓▓▓▓▓▓▓ ◉ ║\n"))
-		reactor.WriteString(lipgloss.NewStyle().Foreground(theme.Primary).Bold(true).Render("     ╚═╗ ◉ ◉ ◉ ╔═╝\n"))
-		reactor.WriteString(lipgloss.NewStyle().Foreground(theme.Accent).Bold(true).Render("       ╚═══════╝"))
+		reactor.WriteString(lipgloss.NewStyle().Foreground(accent).Bold(true).Render("       ╔═══════╗\n"))
+		reactor.WriteString(lipgloss.NewStyle().Foreground(primary).Bold(true).Render("     ╔═╝ ◉ ◉ ◉ ╚═╗\n"))
+		reactor.WriteString(lipgloss.NewStyle().Foreground(primary).Render("    ║ ◉ ▓▓▓▓▓▓▓ ◉ ║\n"))
+		reactor.WriteString(lipgloss.NewStyle().Foreground(accent).Bold(true).Render("    ║ ◉ ▓█████▓ ◉ ║\n"))
+		reactor.WriteString(lipgloss.NewStyle().Foreground(primary).Render("    ║ ◉ ▓▓▓▓▓▓▓ ◉ ║\n"))
+		reactor.WriteString(lipgloss.NewStyle().Foreground(primary).Bold(true).Render("     ╚═╗ ◉ ◉ ◉ ╔═╝\n"))
+		reactor.WriteString(lipgloss.NewStyle().Foreground(accent).Bold(true).Render("       ╚═══════╝"))
 	} else {
-		reactor.WriteString(lipgloss.NewStyle().Foreground(theme.Primary).Render("       ╔═══════╗\n"))
-		reactor.WriteString(lipgloss.NewStyle().Foreground(theme.Primary).Render("     ╔═╝ ◉ ◉ ◉ ╚═╗\n"))
+		reactor.WriteString(lipgloss.NewStyle().Foreground(primary).Render("       ╔═══════╗\n"))
+		reactor.WriteString(lipgloss.NewStyle().Foreground(primary).Render("     ╔═╝ ◉ ◉ ◉ ╚═╗\n"))
 		reactor.WriteString(lipgloss.NewStyle().Foreground(theme.Dim).Render("    ║ ◉ ▓▓▓▓▓▓▓ ◉ ║\n"))
-		reactor.WriteString(lipgloss.NewStyle().Foreground(theme.Primary).Render("    ║ ◉ ▓█████▓ ◉ ║\n"))
+		reactor.WriteString(lipgloss.NewStyle().Foreground(primary).Render("    ║ ◉ ▓█████▓ ◉ ║\n"))
 		reactor.WriteString(lipgloss.NewStyle().Foreground(theme.Dim).Render("    ║ ◉ ▓▓▓▓▓▓▓ ◉ ║\n"))
-		reactor.WriteString(lipgloss.NewStyle().Foreground(theme.Primary).Render("     ╚═╗ ◉ ◉ ◉ ╔═╝\n"))
-		reactor.WriteString(lipgloss.NewStyle().Foreground(theme.Primary).Render("       ╚═══════╝"))
+		reactor.WriteString(lipgloss.NewStyle().Foreground(primary).Render("     ╚═╗ ◉ ◉ ◉ ╔═╝\n"))
+		reactor.WriteString(lipgloss.NewStyle().Foreground(primary).Render("       ╚═══════╝"))
 	}
 
 	return reactor.String()
@@ -1000,85 +1404,223 @@ func tickCommand() tea.Cmd {
 	})
 }
 
-func generateLogCommand() tea.Cmd {
+// generateLogCommand schedules the next simulated log line. The delay and
+// message are drawn up front because rng must only be used from Update.
+func generateLogCommand(rng *rand.Rand) tea.Cmd {
 	// Random delay for log generation
-	duration := time.Duration(rand.Intn(1000)+200) * time.Millisecond
+	duration := time.Duration(rng.Intn(1000)+200) * time.Millisecond
+	opts := []string{
+		"Repulsor calibration complete",
+		"Targeting array locked",
+		"Scanning spectral analysis",
+		"Flight systems check: PASS",
+		"Incoming transmission blocked",
+		"Auxiliary power rerouted",
+		"Nanite density: 98%",
+		"Weather pattern analyzing...",
+	}
+	msg := logMsg(opts[rng.Intn(len(opts))])
 	return tea.Tick(duration, func(t time.Time) tea.Msg {
-		opts := []string{
-			"Repulsor calibration complete",
-			"Targeting array locked",
-			"Scanning spectral analysis",
-			"Flight systems check: PASS",
-			"Incoming transmission blocked",
-			"Auxiliary power rerouted",
-			"Nanite density: 98%",
-			"Weather pattern analyzing...",
-		}
-		return logMsg(opts[rand.Intn(len(opts))])
+		return msg
 	})
 }
 
-func matrixView(m model) string {
-	var sb strings.Builder
+// applyConfig layers user settings from the config file on top of the defaults
+func (m *model) applyConfig(cfg Config) error {
+	if err := m.keys.applyOverrides(cfg.Keys); err != nil {
+		return err
+	}
+	src, err := cfg.Source.metricSource()
+	if err != nil {
+		return err
+	}
+	m.source = src
 
-	// Render grid
-	for y := 0; y < m.matrixRows; y++ {
-		for x := 0; x < m.matrixCols; x++ {
-			if x >= len(m.matrixGrid) || y >= len(m.matrixGrid[x]) {
-				sb.WriteString(" ")
-				continue
-			}
+	if m.widgets, err = newWidgets(cfg.Widgets); err != nil {
+		return err
+	}
+	if m.fleet, err = newFleet(cfg.Agents); err != nil {
+		return err
+	}
+	m.hosts = m.fleet.statuses()
+	m.docker = newDockerClient(cfg.Docker)
+	m.thermal = newThermalReader(cfg.Thermal)
+	if m.thrusterBattery, err = cfg.Power.thrusterBattery(); err != nil {
+		return err
+	}
+	m.supplies = newPowerReader(cfg.Power)
+	m.lowBattery = cfg.Power.lowBattery()
+	m.memReader = &memoryReader{psiRoot: defaultPSIRoot}
+	m.connReader = &connectionReader{}
+	if m.portAllow, err = newPortAllowlist(cfg.Ports); err != nil {
+		return err
+	}
+	if m.audio, err = newAudioSource(cfg.Audio); err != nil {
+		return err
+	}
+	if m.resBinding, err = newResonanceBinding(cfg.Resonance); err != nil {
+		return err
+	}
+	return nil
+}
 
-			char := m.matrixGrid[x][y]
-			headY := m.matrixHeads[x]
-			tailLen := m.matrixTails[x]
-
-			// Determine Color
-			var style lipgloss.Style
-
-			if y == headY {
-				// Head: Bright White/Teal
-				style = lipgloss.NewStyle().Foreground(lipgloss.Color("#FFFFFF")).Bold(true)
-			} else if y < headY && y > headY-tailLen {
-				// Trail: Fade from Teal to Green to Dark
-				dist := headY - y
-				// Simple 3-step gradient
-				if dist < tailLen/3 {
-					style = lipgloss.NewStyle().Foreground(nordTeal)
-				} else if dist < (tailLen*2)/3 {
-					style = lipgloss.NewStyle().Foreground(nordGreen)
-				} else {
-					style = lipgloss.NewStyle().Foreground(cDim) // Fading out
-				}
-				// Determine boldness/faintness
-				if dist > tailLen/2 {
-					style = style.Faint(true)
-				}
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == "agent" {
+		if err := runAgent(os.Args[2:]); err != nil {
+			fmt.Println("Error running agent:", err)
+			os.Exit(1)
+		}
+		return
+	}
 
-			} else {
-				// Off-trail (invisible/dim background noise? or just empty)
-				// True Matrix is empty black sans trail
-				sb.WriteString(" ")
-				continue
-			}
+	configPath := flag.String("config", "", "path to config file (default "+defaultConfigPath()+")")
+	noMouse := flag.Bool("no-mouse", false, "disable mouse support (keeps native terminal text selection)")
+	snapshot := flag.Bool("snapshot", false, "render a single frame to stdout and exit")
+	width := flag.Int("width", 160, "snapshot width in columns")
+	height := flag.Int("height", 48, "snapshot height in rows")
+	plain := flag.Bool("plain", false, "strip colors from snapshot output")
+	output := flag.String("output", "", "also write the snapshot to a file (.html, .svg, .ans or .txt)")
+	seed := flag.Int64("seed", 0, "seed for the animation RNG, to reproduce a session frame-for-frame (default random)")
+	recordCast := flag.String("record", "", "record the terminal output to an asciicast v2 file (.cast)")
+	recordSession := flag.String("record-session", "", "record every message to a session file for --replay")
+	replay := flag.String("replay", "", "replay a session file recorded with --record-session")
+	speed := flag.Float64("speed", 1, "replay speed multiplier")
+	step := flag.Bool("step", false, "start the replay paused, stepping one event at a time")
+	metricsAddr := flag.String("metrics-addr", "", "serve Prometheus metrics at http://ADDR/metrics (e.g. :9101)")
+	audioInput := flag.String("audio", "", "visualize raw PCM from a file, FIFO or - for stdin (overrides audio.input)")
+	flag.Parse()
+
+	path, required := *configPath, true
+	if path == "" {
+		path, required = defaultConfigPath(), false
+	}
+	cfg, err := loadConfig(path, required)
+	if err != nil {
+		fmt.Println("Error loading config:", err)
+		os.Exit(1)
+	}
+	if *audioInput != "" {
+		if cfg.Audio == nil {
+			cfg.Audio = &AudioConfig{}
+		}
+		cfg.Audio.Input = *audioInput
+	}
 
-			// Adjust for Rune Width (Katakana is nice but standard terminal grid is easiest with space)
-			// But runes variable width. Let's just print.
-			// Force 1 cell width?
+	m := initialModel()
+	if err := m.applyConfig(cfg); err != nil {
+		fmt.Println("Error applying config:", err)
+		os.Exit(1)
+	}
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+	m.rng = rand.New(rand.NewSource(*seed))
 
-			// Hack: Runewidth. Or just add a space after?
-			// Katakana is often half-width in modern terms but might render wider.
-			// Let's stick to simple rendering.
-			sb.WriteString(style.Render(string(char)))
+	if *snapshot {
+		err := runSnapshot(m, snapshotOptions{width: *width, height: *height, plain: *plain, output: *output}, os.Stdout)
+		if err != nil {
+			fmt.Println("Error rendering snapshot:", err)
+			os.Exit(1)
 		}
-		sb.WriteString("\n")
+		return
 	}
-	return sb.String()
-}
 
-func main() {
-	p := tea.NewProgram(initialModel(), tea.WithAltScreen())
+	if *replay != "" {
+		if err := runReplay(m, *replay, *speed, *step); err != nil {
+			fmt.Println("Error replaying session:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// A replay must not publish recorded readings as this machine's metrics,
+	// so the exporter only serves live sessions
+	if *metricsAddr != "" {
+		m.exporter = newExporter()
+		if err := serveMetrics(*metricsAddr, m.exporter); err != nil {
+			fmt.Println("Error starting metrics server:", err)
+			os.Exit(1)
+		}
+	}
+
+	m.fleet.start()
+	defer m.fleet.close()
+	m.audio.start()
+	defer m.audio.close()
+
+	opts := []tea.ProgramOption{tea.WithAltScreen()}
+	if m.audio != nil && m.audio.input == "-" {
+		// Stdin carries the audio, so keys come from the terminal
+		opts = append(opts, tea.WithInputTTY())
+	}
+	if !*noMouse {
+		opts = append(opts, tea.WithMouseCellMotion())
+	}
+
+	var filters []func(tea.Model, tea.Msg) tea.Msg
+	if *recordSession != "" {
+		f, err := os.Create(*recordSession)
+		if err != nil {
+			fmt.Println("Error recording session:", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		rec, err := newSessionWriter(f, *seed, time.Now)
+		if err != nil {
+			fmt.Println("Error recording session:", err)
+			os.Exit(1)
+		}
+		defer func() {
+			if err := rec.Close(); err != nil {
+				fmt.Println("Error recording session:", err)
+			}
+		}()
+		filters = append(filters, rec.filter)
+	}
+
+	if *recordCast != "" {
+		f, err := os.Create(*recordCast)
+		if err != nil {
+			fmt.Println("Error recording cast:", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		w, h, err := term.GetSize(os.Stdout.Fd())
+		if err != nil {
+			w, h = 80, 24
+		}
+		cast, err := newCastRecorder(os.Stdout, f, w, h, time.Now)
+		if err != nil {
+			fmt.Println("Error recording cast:", err)
+			os.Exit(1)
+		}
+		defer func() {
+			if err := cast.Close(); err != nil {
+				fmt.Println("Error recording cast:", err)
+			}
+		}()
+		opts = append(opts, tea.WithOutput(cast))
+		filters = append(filters, cast.filter)
+	}
+
+	if len(filters) > 0 {
+		opts = append(opts, tea.WithFilter(chainFilters(filters)))
+	}
+
+	p := tea.NewProgram(m, opts...)
 	if _, err := p.Run(); err != nil {
 		fmt.Println("Error starting J.A.R.V.I.S.:", err)
 	}
 }
+
+// chainFilters runs message filters in order; a nil result drops the message
+func chainFilters(filters []func(tea.Model, tea.Msg) tea.Msg) func(tea.Model, tea.Msg) tea.Msg {
+	return func(m tea.Model, msg tea.Msg) tea.Msg {
+		for _, f := range filters {
+			if msg = f(m, msg); msg == nil {
+				return nil
+			}
+		}
+		return msg
+	}
+}
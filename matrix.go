@@ -0,0 +1,139 @@
+package main
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// Matrix rain is the largest thing on screen, so it is drawn without
+// lipgloss in the inner loop: every gradient step's escape sequences are
+// rendered once, cells of the same step share one sequence, and the frame
+// is built in a buffer kept between frames.
+
+// rainStep is a cell's place in the gradient from head to tail
+type rainStep int
+
+const (
+	rainBlank rainStep = iota
+	rainHead
+	rainTeal
+	rainTealFaint
+	rainGreen
+	rainGreenFaint
+	rainDim
+	rainDimFaint
+	rainSteps
+)
+
+// rainStyles are the lipgloss styles behind each step
+func rainStyles() [rainSteps]lipgloss.Style {
+	teal := lipgloss.NewStyle().Foreground(nordTeal)
+	green := lipgloss.NewStyle().Foreground(nordGreen)
+	dim := lipgloss.NewStyle().Foreground(cDim)
+	return [rainSteps]lipgloss.Style{
+		rainHead:       lipgloss.NewStyle().Foreground(lipgloss.Color("#FFFFFF")).Bold(true),
+		rainTeal:       teal,
+		rainTealFaint:  teal.Faint(true),
+		rainGreen:      green,
+		rainGreenFaint: green.Faint(true),
+		rainDim:        dim,
+		rainDimFaint:   dim.Faint(true),
+	}
+}
+
+// rainSGR is the escape sequence that starts and ends a step's style
+type rainSGR struct {
+	open, close string
+}
+
+// matrixRenderer holds what matrixView reuses from frame to frame
+type matrixRenderer struct {
+	profile termenv.Profile
+	ready   bool
+	sgr     [rainSteps]rainSGR
+	buf     []byte
+}
+
+func newMatrixRenderer() *matrixRenderer {
+	return &matrixRenderer{}
+}
+
+// styles renders each step around a marker once and keeps what lipgloss
+// put either side of it. They are rebuilt if the color profile changes.
+func (r *matrixRenderer) styles() *[rainSteps]rainSGR {
+	profile := lipgloss.ColorProfile()
+	if r.ready && r.profile == profile {
+		return &r.sgr
+	}
+	const marker = "\x00"
+	for step, style := range rainStyles() {
+		if rainStep(step) == rainBlank {
+			continue
+		}
+		open, close, _ := strings.Cut(style.Render(marker), marker)
+		r.sgr[step] = rainSGR{open: open, close: close}
+	}
+	r.profile, r.ready = profile, true
+	return &r.sgr
+}
+
+// rainStepAt places row y of a column on its trail
+func rainStepAt(y, headY, tailLen int) rainStep {
+	if y == headY {
+		return rainHead
+	}
+	if y >= headY || y <= headY-tailLen {
+		return rainBlank
+	}
+	// Three colors from teal to dim, the older half faint
+	dist := headY - y
+	step := rainDim
+	if dist < tailLen/3 {
+		step = rainTeal
+	} else if dist < (tailLen*2)/3 {
+		step = rainGreen
+	}
+	if dist > tailLen/2 {
+		step++
+	}
+	return step
+}
+
+func (r *matrixRenderer) render(m model) string {
+	sgr := r.styles()
+	buf := r.buf[:0]
+
+	for y := 0; y < m.matrixRows; y++ {
+		current := rainBlank
+		for x := 0; x < m.matrixCols; x++ {
+			step, char := rainBlank, ' '
+			if x < len(m.matrixGrid) && y < len(m.matrixGrid[x]) {
+				if step = rainStepAt(y, m.matrixHeads[x], m.matrixTails[x]); step != rainBlank {
+					char = m.matrixGrid[x][y]
+				}
+			}
+			// Only a change of step costs an escape sequence
+			if step != current {
+				buf = append(buf, sgr[current].close...)
+				buf = append(buf, sgr[step].open...)
+				current = step
+			}
+			buf = utf8.AppendRune(buf, char)
+		}
+		buf = append(buf, sgr[current].close...)
+		buf = append(buf, '\n')
+	}
+
+	r.buf = buf
+	return string(buf)
+}
+
+func matrixView(m model) string {
+	if m.rain == nil {
+		return newMatrixRenderer().render(m)
+	}
+	return m.rain.render(m)
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// matrixViewPerCell is the straightforward renderer: one lipgloss style and
+// Render call per visible cell. It is the reference for matrixView.
+func matrixViewPerCell(m model) string {
+	styles := rainStyles()
+	var sb strings.Builder
+	for y := 0; y < m.matrixRows; y++ {
+		for x := 0; x < m.matrixCols; x++ {
+			if x >= len(m.matrixGrid) || y >= len(m.matrixGrid[x]) {
+				sb.WriteString(" ")
+				continue
+			}
+			step := rainStepAt(y, m.matrixHeads[x], m.matrixTails[x])
+			if step == rainBlank {
+				sb.WriteString(" ")
+				continue
+			}
+			sb.WriteString(styles[step].Render(string(m.matrixGrid[x][y])))
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// styledCell is a character with the escape sequence in effect for it
+type styledCell struct {
+	char rune
+	sgr  string
+}
+
+// parseCells splits terminal output into cells, tracking the last SGR
+// sequence so that outputs differing only in how often they repeat an
+// escape sequence compare equal
+func parseCells(s string) []styledCell {
+	var cells []styledCell
+	sgr := ""
+	for len(s) > 0 {
+		if strings.HasPrefix(s, "\x1b[") {
+			end := strings.IndexByte(s, 'm')
+			if seq := s[2:end]; seq == "0" || seq == "" {
+				sgr = ""
+			} else {
+				sgr = seq
+			}
+			s = s[end+1:]
+			continue
+		}
+		r := []rune(s)[0]
+		if r == ' ' || r == '\n' {
+			// Blank cells look the same in any style
+			cells = append(cells, styledCell{char: r})
+		} else {
+			cells = append(cells, styledCell{char: r, sgr: sgr})
+		}
+		s = s[len(string(r)):]
+	}
+	return cells
+}
+
+// rainModel is a model sized to width x height with the rain mid-fall
+func rainModel(width, height int) model {
+	m := initialModel()
+	m.now = func() time.Time { return testTime }
+	m.rng = rand.New(rand.NewSource(1))
+	m.source = fakeSource{}
+	tm, _ := m.Update(tea.WindowSizeMsg{Width: width, Height: height})
+	m = tm.(model)
+	for i := 0; i < 30; i++ {
+		tm, _ = m.Update(tickMsg(testTime))
+		m = tm.(model)
+	}
+	return m
+}
+
+func TestMatrixViewMatchesPerCellStyles(t *testing.T) {
+	// Golden tests need the profile TestMain set, whatever runs after this
+	saved := lipgloss.ColorProfile()
+	t.Cleanup(func() { lipgloss.SetColorProfile(saved) })
+	for _, profile := range []termenv.Profile{termenv.TrueColor, termenv.ANSI256, termenv.Ascii} {
+		lipgloss.SetColorProfile(profile)
+		m := rainModel(200, 60)
+		got, want := parseCells(matrixView(m)), parseCells(matrixViewPerCell(m))
+		if len(got) != len(want) {
+			t.Fatalf("profile %v: %d cells, want %d", profile, len(got), len(want))
+		}
+		for i := range got {
+			if got[i] != want[i] {
+				t.Fatalf("profile %v: cell %d = %+v, want %+v", profile, i, got[i], want[i])
+			}
+		}
+		// A second frame reuses the buffer without bleeding into the first
+		first := matrixView(m)
+		if second := matrixView(m); second != first {
+			t.Fatalf("profile %v: frames differ", profile)
+		}
+	}
+}
+
+func BenchmarkMatrixView(b *testing.B) {
+	m := rainModel(200, 60)
+	b.ReportAllocs()
+	for b.Loop() {
+		matrixView(m)
+	}
+}
+
+func BenchmarkMatrixViewPerCell(b *testing.B) {
+	m := rainModel(200, 60)
+	b.ReportAllocs()
+	for b.Loop() {
+		matrixViewPerCell(m)
+	}
+}
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/shirou/gopsutil/v3/load"
+	"github.com/shirou/gopsutil/v3/mem"
+)
+
+const (
+	defaultPSIRoot = "/proc/pressure"
+
+	// Thresholds on the "some" avg10 share of time stalled, in percent
+	psiWarn = 10.0
+	psiCrit = 40.0
+)
+
+// psiResources are the files under /proc/pressure, in display order
+var psiResources = []string{"cpu", "memory", "io"}
+
+// memoryInfo is the breakdown behind the single memory percentage
+type memoryInfo struct {
+	Total     uint64 `json:"total"`
+	Used      uint64 `json:"used"`
+	Cached    uint64 `json:"cached"`
+	Buffers   uint64 `json:"buffers"`
+	Available uint64 `json:"available"`
+	SwapTotal uint64 `json:"swap_total"`
+	SwapUsed  uint64 `json:"swap_used"`
+}
+
+// psiLine is one line of a pressure file: the share of wall time, in
+// percent, that some (or all) tasks were stalled on the resource
+type psiLine struct {
+	Avg10  float64 `json:"avg10"`
+	Avg60  float64 `json:"avg60"`
+	Avg300 float64 `json:"avg300"`
+	Total  uint64  `json:"total"` // cumulative stall time in µs
+}
+
+type psiStat struct {
+	Resource string   `json:"resource"`
+	Some     psiLine  `json:"some"`
+	Full     *psiLine `json:"full,omitempty"` // absent for cpu before Linux 5.13
+}
+
+type memoryMsg struct {
+	mem    memoryInfo
+	load   load.AvgStat
+	cpus   int
+	psi    []psiStat
+	psiErr error // PSI needs Linux 4.20+ with CONFIG_PSI
+	err    error
+}
+
+// memoryReader samples memory, load and pressure on this machine
+type memoryReader struct {
+	psiRoot string
+}
+
+func (r *memoryReader) read() memoryMsg {
+	msg := memoryMsg{cpus: runtime.NumCPU()}
+
+	vm, err := mem.VirtualMemory()
+	if err != nil {
+		msg.err = err
+		return msg
+	}
+	msg.mem = memoryInfo{
+		Total:     vm.Total,
+		Used:      vm.Used,
+		Cached:    vm.Cached,
+		Buffers:   vm.Buffers,
+		Available: vm.Available,
+	}
+	if swap, err := mem.SwapMemory(); err == nil {
+		msg.mem.SwapTotal, msg.mem.SwapUsed = swap.Total, swap.Used
+	}
+	if avg, err := load.Avg(); err == nil {
+		msg.load = *avg
+	}
+	msg.psi, msg.psiErr = readPSI(r.psiRoot)
+	return msg
+}
+
+// readPSI reads every pressure file under root
+func readPSI(root string) ([]psiStat, error) {
+	stats := make([]psiStat, 0, len(psiResources))
+	for _, res := range psiResources {
+		f, err := os.Open(filepath.Join(root, res))
+		if err != nil {
+			return nil, err
+		}
+		st, err := parsePSI(res, f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", res, err)
+		}
+		stats = append(stats, st)
+	}
+	return stats, nil
+}
+
+// parsePSI parses a pressure file:
+//
+//	some avg10=0.00 avg60=0.00 avg300=0.00 total=0
+//	full avg10=0.00 avg60=0.00 avg300=0.00 total=0
+func parsePSI(resource string, r io.Reader) (psiStat, error) {
+	st := psiStat{Resource: resource}
+	sc := bufio.NewScanner(r)
+	seen := false
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		var line psiLine
+		for _, f := range fields[1:] {
+			key, val, ok := strings.Cut(f, "=")
+			if !ok {
+				return st, fmt.Errorf("malformed field %q", f)
+			}
+			var err error
+			switch key {
+			case "avg10":
+				line.Avg10, err = strconv.ParseFloat(val, 64)
+			case "avg60":
+				line.Avg60, err = strconv.ParseFloat(val, 64)
+			case "avg300":
+				line.Avg300, err = strconv.ParseFloat(val, 64)
+			case "total":
+				line.Total, err = strconv.ParseUint(val, 10, 64)
+			}
+			if err != nil {
+				return st, fmt.Errorf("%s: %w", key, err)
+			}
+		}
+		switch fields[0] {
+		case "some":
+			st.Some, seen = line, true
+		case "full":
+			st.Full = &line
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return st, err
+	}
+	if !seen {
+		return st, errors.New("no \"some\" line")
+	}
+	return st, nil
+}
+
+func collectMemoryCommand(r *memoryReader) tea.Cmd {
+	return func() tea.Msg {
+		return r.read()
+	}
+}
+
+// --- Model glue ---
+
+func (m model) renderMemoryPanel(width, height int) string {
+	theme := m.getTheme()
+	label := lipgloss.NewStyle().Foreground(theme.Dim)
+	value := lipgloss.NewStyle().Foreground(theme.Primary)
+	section := lipgloss.NewStyle().Foreground(theme.Accent).Bold(true)
+
+	lines := []string{headerStyle.Render("MEMORY & PRESSURE"), ""}
+	if m.memory.err != nil || m.memory.mem.Total == 0 {
+		msg := label.Render("No data")
+		if m.memory.err != nil {
+			msg = lipgloss.NewStyle().Foreground(alertRed).Render(m.memory.err.Error())
+		}
+		lines = append(lines, msg)
+		return m.panelStyle(panelMemory).Width(width).Height(height).
+			Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+	}
+
+	// Memory: one bar per part, as a share of physical RAM (swap of its own size)
+	mi := m.memory.mem
+	barWidth := max(width-30, 5)
+	part := func(name string, used, total uint64, color lipgloss.Color) string {
+		frac := 0.0
+		if total > 0 {
+			frac = float64(used) / float64(total)
+		}
+		return label.Render(fmt.Sprintf("%-8s", name)) +
+			lipgloss.NewStyle().Foreground(color).Render(usageBar(frac, barWidth)) +
+			value.Render(fmt.Sprintf(" %3.0f%% %10s", frac*100, formatBytes(used)))
+	}
+	lines = append(lines,
+		section.Render("MEMORY ")+label.Render(formatBytes(mi.Available)+" available of "+formatBytes(mi.Total)),
+		part("USED", mi.Used, mi.Total, theme.Primary),
+		part("CACHED", mi.Cached, mi.Total, nordTeal),
+		part("BUFFERS", mi.Buffers, mi.Total, nordBlue),
+		part("SWAP", mi.SwapUsed, mi.SwapTotal, theme.Accent),
+		"",
+	)
+
+	// Load: colored against the number of CPUs
+	ld := m.memory.load
+	loadStyle := func(v float64) string {
+		color := theme.Primary
+		if cpus := float64(max(m.memory.cpus, 1)); v >= 2*cpus {
+			color = alertRed
+		} else if v >= cpus {
+			color = alertYellow
+		}
+		return lipgloss.NewStyle().Foreground(color).Render(fmt.Sprintf("%6.2f", v))
+	}
+	lines = append(lines,
+		section.Render("LOAD    ")+loadStyle(ld.Load1)+loadStyle(ld.Load5)+loadStyle(ld.Load15)+
+			label.Render(fmt.Sprintf("   1m/5m/15m, %d CPUs", m.memory.cpus)),
+		"",
+	)
+
+	// Pressure: some and full stall percentages over 10s, 1m and 5m
+	lines = append(lines, section.Render("PRESSURE")+label.Render("         AVG10  AVG60 AVG300"))
+	if m.memory.psiErr != nil {
+		reason := m.memory.psiErr.Error()
+		if errors.Is(m.memory.psiErr, os.ErrNotExist) {
+			reason = "not supported by this kernel"
+		}
+		lines = append(lines, label.Render("PSI "+reason))
+	}
+	row := func(name, kind string, l psiLine) string {
+		color := theme.Primary
+		switch {
+		case l.Avg10 >= psiCrit:
+			color = alertRed
+		case l.Avg10 >= psiWarn:
+			color = alertYellow
+		}
+		return label.Render(fmt.Sprintf("%-8s%-6s", name, kind)) +
+			lipgloss.NewStyle().Foreground(color).Render(fmt.Sprintf("%8.2f%7.2f%7.2f", l.Avg10, l.Avg60, l.Avg300))
+	}
+	for _, st := range m.memory.psi {
+		lines = append(lines, row(strings.ToUpper(st.Resource), "some", st.Some))
+		if st.Full != nil {
+			lines = append(lines, row("", "full", *st.Full))
+		}
+	}
+
+	return m.panelStyle(panelMemory).Width(width).Height(height).
+		Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
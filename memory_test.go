@@ -0,0 +1,69 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/shirou/gopsutil/v3/load"
+)
+
+func testMemory() memoryMsg {
+	return memoryMsg{
+		mem: memoryInfo{
+			Total: 16 << 30, Used: 7 << 30, Cached: 5 << 30, Buffers: 512 << 20, Available: 8 << 30,
+			SwapTotal: 4 << 30, SwapUsed: 1 << 30,
+		},
+		load: load.AvgStat{Load1: 9.5, Load5: 6.25, Load15: 3.1},
+		cpus: 8,
+		psi: []psiStat{
+			{Resource: "cpu", Some: psiLine{Avg10: 12.5, Avg60: 8.1, Avg300: 3.2}},
+			{Resource: "memory", Some: psiLine{Avg10: 0.4, Avg60: 0.2}, Full: &psiLine{Avg10: 0.1}},
+			{Resource: "io", Some: psiLine{Avg10: 44.9, Avg60: 30, Avg300: 12.75}, Full: &psiLine{Avg10: 41, Avg60: 28.5, Avg300: 11}},
+		},
+	}
+}
+
+func TestParsePSI(t *testing.T) {
+	st, err := parsePSI("io", strings.NewReader(
+		"some avg10=1.50 avg60=0.75 avg300=0.25 total=123456\n"+
+			"full avg10=0.50 avg60=0.10 avg300=0.00 total=4567\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Some != (psiLine{Avg10: 1.5, Avg60: 0.75, Avg300: 0.25, Total: 123456}) || st.Full == nil || st.Full.Total != 4567 {
+		t.Errorf("io = %+v full %+v", st, st.Full)
+	}
+
+	// Kernels before 5.13 have no full line for cpu
+	st, err = parsePSI("cpu", strings.NewReader("some avg10=0.00 avg60=0.00 avg300=0.00 total=0\n"))
+	if err != nil || st.Full != nil {
+		t.Errorf("cpu = %+v, %v", st, err)
+	}
+
+	for _, bad := range []string{"", "some avg10\n", "some avg10=x\n"} {
+		if _, err := parsePSI("cpu", strings.NewReader(bad)); err == nil {
+			t.Errorf("parsePSI(%q) succeeded", bad)
+		}
+	}
+}
+
+func TestReadPSI(t *testing.T) {
+	root := t.TempDir()
+	line := "some avg10=2.00 avg60=1.00 avg300=0.50 total=10"
+	writeTree(t, root, map[string]string{"cpu": line, "memory": line, "io": line})
+
+	stats, err := readPSI(root)
+	if err != nil || len(stats) != 3 || stats[2].Resource != "io" || stats[2].Some.Avg10 != 2 {
+		t.Errorf("readPSI = %+v, %v", stats, err)
+	}
+
+	m := newTestModel(t, 120, 40)
+	msg := testMemory()
+	msg.psi, msg.psiErr = readPSI(filepath.Join(root, "missing"))
+	m = step(m, msg)
+	m.setPage(pageProcesses)
+	if !strings.Contains(m.View(), "PSI not supported by this kernel") {
+		t.Error("missing /proc/pressure is not explained")
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/shirou/gopsutil/v3/cpu"
+	"github.com/shirou/gopsutil/v3/mem"
+	"github.com/shirou/gopsutil/v3/net"
+)
+
+// Sample is one reading of the headline vitals shown in the left panel
+type Sample struct {
+	CPUPercent     float64 // 0-100, all cores
+	MemUsedPercent float64 // 0-100
+	NetBytesSent   uint64  // cumulative, all interfaces
+	NetBytesRecv   uint64  // cumulative, all interfaces
+}
+
+// MetricSource supplies vitals samples. The local source reads gopsutil;
+// tests and remote backends provide their own.
+type MetricSource interface {
+	Sample() (Sample, error)
+}
+
+// HistorySource is implemented by sources that can backfill a metric's
+// recent values, oldest first, so the resonance strip starts full instead
+// of empty
+type HistorySource interface {
+	History(metric string, window, step time.Duration) ([]float64, error)
+}
+
+// HostSource is implemented by sources that stand in for another machine
+// entirely. Collectors that can only read this machine stay off while one
+// is configured, so pages never mix two hosts.
+type HostSource interface {
+	Host() string
+}
+
+// DiskSource, IfaceSource and ProcessSource are implemented by remote
+// sources that also replace the local storage, interface and process
+// collectors
+type DiskSource interface {
+	Disks() ([]diskInfo, error)
+}
+
+type IfaceSource interface {
+	Ifaces() ([]net.IOCountersStat, error)
+}
+
+type ProcessSource interface {
+	Processes() ([]processInfo, error)
+}
+
+// sampleMsg carries a finished sample back to Update
+type sampleMsg struct {
+	sample Sample
+	err    error
+}
+
+// localSource samples the machine JARVIS is running on
+type localSource struct{}
+
+func (localSource) Sample() (Sample, error) {
+	var s Sample
+
+	// CPU Usage
+	percentages, err := cpu.Percent(0, false)
+	if err != nil {
+		return s, err
+	}
+	if len(percentages) > 0 {
+		s.CPUPercent = percentages[0]
+	}
+
+	// Memory Usage
+	vmem, err := mem.VirtualMemory()
+	if err != nil {
+		return s, err
+	}
+	s.MemUsedPercent = vmem.UsedPercent
+
+	// Network I/O totals across all interfaces
+	netStats, err := net.IOCounters(false)
+	if err != nil {
+		return s, err
+	}
+	if len(netStats) > 0 {
+		s.NetBytesSent = netStats[0].BytesSent
+		s.NetBytesRecv = netStats[0].BytesRecv
+	}
+
+	return s, nil
+}
+
+// collectSampleCommand samples src off the UI goroutine
+func collectSampleCommand(src MetricSource) tea.Cmd {
+	return func() tea.Msg {
+		s, err := src.Sample()
+		return sampleMsg{sample: s, err: err}
+	}
+}
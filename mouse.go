@@ -0,0 +1,64 @@
+package main
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// wheelStep is the number of lines scrolled per mouse wheel notch
+const wheelStep = 3
+
+func (m model) updateMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	// Overlays swallow the mouse so clicks don't land on hidden panels
+	if m.palette.active || m.showHelp || m.showAlerts {
+		return m, nil
+	}
+
+	// The tab bar sits directly below the title
+	if msg.Y == headerHeight-1 && msg.Button == tea.MouseButtonLeft && msg.Action == tea.MouseActionPress {
+		if i, ok := m.tabAt(msg.X); ok {
+			m.setPage(i)
+		}
+		return m, nil
+	}
+
+	r, ok := m.panelAt(msg.X, msg.Y)
+	if !ok {
+		return m, nil
+	}
+
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		m.scrollPanel(r.id, -wheelStep)
+
+	case tea.MouseButtonWheelDown:
+		m.scrollPanel(r.id, wheelStep)
+
+	case tea.MouseButtonLeft:
+		if msg.Action != tea.MouseActionPress {
+			break
+		}
+		m.focus = r.id
+
+		if a, ok := m.alertRect(); ok && a.contains(msg.X, msg.Y) {
+			m.acknowledgeAlert()
+		}
+
+		if r.id == panelFleet {
+			if idx, ok := m.fleetCardAt(msg.X-r.x, msg.Y-r.y, r.w, r.h); ok {
+				m.fleetCursor = idx
+			}
+		}
+
+		// Clicking a table row selects it; on the alert log it also acknowledges
+		if t := m.tableFor(r.id); t != nil {
+			if row, ok := t.rowAt(msg.Y - r.y - tableTop); ok {
+				t.setCursor(row)
+				if r.id == panelAlerts {
+					m.acknowledgeAlertAt(row)
+				}
+			}
+		}
+	}
+
+	return m, nil
+}
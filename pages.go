@@ -0,0 +1,317 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// page is a named dashboard screen. Its panels are laid out as equal-width
+// columns, each column stacking its panels with equal heights.
+type page struct {
+	name    string
+	columns [][]panelID
+	focus   panelID // panel focused when the page is opened
+	key     string  // default key, for pages past the tenth number key
+}
+
+const (
+	pageOverview = iota
+	pageProcesses
+	pageNetwork
+	pageStorage
+	pageLogs
+	pageAlerts
+	pageServices
+	pageFleet
+	pageContainers
+	pageSensors
+	pagePorts
+)
+
+var pages = []page{
+	pageOverview: {
+		name:    "OVERVIEW",
+		columns: [][]panelID{{panelVitals}, {panelReactor}, {panelTelemetry}},
+		focus:   panelTelemetry,
+	},
+	pageProcesses: {
+		name:    "PROCESSES",
+		columns: [][]panelID{{panelProcesses}, {panelMemory}},
+		focus:   panelProcesses,
+	},
+	pageNetwork: {
+		name:    "NETWORK",
+		columns: [][]panelID{{panelNetwork, panelConnections}},
+		focus:   panelNetwork,
+	},
+	pageStorage: {
+		name:    "STORAGE",
+		columns: [][]panelID{{panelStorage}},
+		focus:   panelStorage,
+	},
+	pageLogs: {
+		name:    "LOGS",
+		columns: [][]panelID{{panelTelemetry}},
+		focus:   panelTelemetry,
+	},
+	pageAlerts: {
+		name:    "ALERTS",
+		columns: [][]panelID{{panelAlerts}},
+		focus:   panelAlerts,
+	},
+	pageServices: {
+		name:    "SERVICES",
+		columns: [][]panelID{{panelServices}},
+		focus:   panelServices,
+	},
+	pageFleet: {
+		name:    "FLEET",
+		columns: [][]panelID{{panelFleet}},
+		focus:   panelFleet,
+	},
+	pageContainers: {
+		name:    "CONTAINERS",
+		columns: [][]panelID{{panelContainers}},
+		focus:   panelContainers,
+	},
+	pageSensors: {
+		name:    "SENSORS",
+		columns: [][]panelID{{panelSensors}, {panelPower}},
+		focus:   panelSensors,
+	},
+	pagePorts: {
+		name:    "PORTS",
+		columns: [][]panelID{{panelPorts}},
+		focus:   panelPorts,
+		key:     "o",
+	},
+}
+
+// pageKey is the default key that opens page i: 1-9, then 0 for the tenth,
+// then the page's own key
+func pageKey(i int) string {
+	if i >= 10 {
+		return pages[i].key
+	}
+	return strconv.Itoa((i + 1) % 10)
+}
+
+func (m model) currentPage() page {
+	return pages[m.page%len(pages)]
+}
+
+// setPage switches the visible page and re-lays out its components
+func (m *model) setPage(idx int) {
+	if idx < 0 || idx >= len(pages) {
+		return
+	}
+	m.page = idx
+	m.zoomed = false
+	m.focus = pages[idx].focus
+	m.resize()
+}
+
+func (m model) renderTabBar() string {
+	theme := m.getTheme()
+
+	activeStyle := lipgloss.NewStyle().Foreground(theme.Background).Background(theme.Primary).Bold(true).Padding(0, 1)
+	inactiveStyle := lipgloss.NewStyle().Foreground(theme.Dim).Padding(0, 1)
+
+	tabs := make([]string, len(pages))
+	for i := range pages {
+		label := m.tabLabel(i)
+		if i == m.page {
+			tabs[i] = activeStyle.Render(label)
+		} else {
+			tabs[i] = inactiveStyle.Render(label)
+		}
+	}
+
+	return lipgloss.NewStyle().Width(m.width).MaxWidth(m.width).
+		Render(lipgloss.JoinHorizontal(lipgloss.Top, tabs...))
+}
+
+// tabLabel names a tab. When the full labels don't fit the terminal,
+// inactive tabs shrink to their number so the bar stays on one row.
+func (m model) tabLabel(i int) string {
+	if i != m.page && m.compactTabs() {
+		return pageKey(i)
+	}
+	return fullTabLabel(i)
+}
+
+func fullTabLabel(i int) string {
+	return pageKey(i) + " " + pages[i].name
+}
+
+func (m model) compactTabs() bool {
+	total := 0
+	for i := range pages {
+		// Tabs are padded by one cell on each side
+		total += lipgloss.Width(fullTabLabel(i)) + 2
+	}
+	return total > m.width
+}
+
+// tabAt returns the page whose tab covers column x of the tab bar
+func (m model) tabAt(x int) (int, bool) {
+	left := 0
+	for i := range pages {
+		w := lipgloss.Width(m.tabLabel(i)) + 2
+		if x >= left && x < left+w {
+			return i, true
+		}
+		left += w
+	}
+	return 0, false
+}
+
+// tableFor returns the table shown by a panel, if it has one
+func (m *model) tableFor(id panelID) *dataTable {
+	switch id {
+	case panelProcesses:
+		return &m.procTable
+	case panelNetwork:
+		return &m.ifaceTable
+	case panelStorage:
+		return &m.diskTable
+	case panelAlerts:
+		return &m.alertTable
+	case panelContainers:
+		return &m.containerTable
+	case panelSensors:
+		return &m.sensorTable
+	case panelConnections:
+		return &m.connTable
+	case panelPorts:
+		return &m.portTable
+	}
+	return nil
+}
+
+// tableTop is the number of rows from a table panel's top edge to its
+// table header: border, padding, then the panel header and its margin
+const tableTop = 4
+
+func (m model) renderTablePanel(id panelID, title string, t dataTable, width, height int) string {
+	content := lipgloss.JoinVertical(lipgloss.Left,
+		headerStyle.Render(title),
+		t.view(width-2, m.getTheme(), m.focus == id),
+	)
+	return m.panelStyle(id).Width(width).Height(height).Render(content)
+}
+
+// --- Page data ---
+
+func (m *model) setProcesses(procs []processInfo) {
+	// Keep the selection on the same process across refreshes
+	var selected int32 = -1
+	if m.procTable.cursor < len(m.processes) {
+		selected = m.processes[m.procTable.cursor].PID
+	}
+
+	m.processes = procs
+	rows := make([][]string, len(procs))
+	cursor := 0
+	for i, p := range procs {
+		rows[i] = []string{
+			strconv.Itoa(int(p.PID)),
+			p.Name,
+			fmt.Sprintf("%.1f", p.CPU),
+			fmt.Sprintf("%.1f", p.Mem),
+			formatBytes(p.RSS),
+		}
+		if p.PID == selected {
+			cursor = i
+		}
+	}
+	m.procTable.setRows(rows)
+	m.procTable.setCursor(cursor)
+}
+
+// jumpToProcess opens the process page with pid selected
+func (m *model) jumpToProcess(pid int32) {
+	m.setPage(pageProcesses)
+	for i, p := range m.processes {
+		if p.PID == pid {
+			m.procTable.setCursor(i)
+			return
+		}
+	}
+}
+
+func (m *model) setDisks(disks []diskInfo) {
+	m.disks = disks
+	rows := make([][]string, len(disks))
+	for i, d := range disks {
+		rows[i] = []string{
+			d.Mount,
+			d.FSType,
+			formatBytes(d.Total),
+			formatBytes(d.Used),
+			formatBytes(d.Free),
+			usageBar(d.UsedPercent/100, 10) + fmt.Sprintf(" %3.0f%%", d.UsedPercent),
+		}
+	}
+	m.diskTable.setRows(rows)
+}
+
+// setIfaces converts cumulative interface counters into per-second rates
+// against the previous sample
+func (m *model) setIfaces(msg ifacesMsg) {
+	prev := make(map[string]uint64)
+	prevTx := make(map[string]uint64)
+	for _, s := range m.lastIfaces.stats {
+		prev[s.Name] = s.BytesRecv
+		prevTx[s.Name] = s.BytesSent
+	}
+	elapsed := msg.at.Sub(m.lastIfaces.at).Seconds()
+
+	rows := make([][]string, 0, len(msg.stats))
+	for _, s := range msg.stats {
+		rx, tx := "-", "-"
+		// Counters can reset when an interface restarts; skip that sample
+		if last, ok := prev[s.Name]; ok && elapsed > 0 && s.BytesRecv >= last && s.BytesSent >= prevTx[s.Name] {
+			rx = formatBytes(uint64(float64(s.BytesRecv-last)/elapsed)) + "/s"
+			tx = formatBytes(uint64(float64(s.BytesSent-prevTx[s.Name])/elapsed)) + "/s"
+		}
+		rows = append(rows, []string{
+			s.Name,
+			rx,
+			tx,
+			formatBytes(s.BytesRecv),
+			formatBytes(s.BytesSent),
+			strconv.FormatUint(s.Errin+s.Errout, 10),
+		})
+	}
+
+	m.lastIfaces = msg
+	m.ifaceTable.setRows(rows)
+}
+
+// refreshAlertTable rebuilds the alert page rows, newest first, and
+// republishes the alert state to the exporter
+func (m *model) refreshAlertTable() {
+	rows := make([][]string, len(m.alertHistory))
+	for i := range m.alertHistory {
+		a := m.alertHistory[len(m.alertHistory)-1-i]
+		ack := ""
+		if a.Acked {
+			ack = "ACK"
+		}
+		rows[i] = []string{a.Time.Format(time.TimeOnly), severityName(a.Severity), a.Message, ack}
+	}
+	m.alertTable.setRows(rows)
+	m.publishAlerts()
+}
+
+// usageBar draws a fixed-width block bar for a 0-1 fraction
+func usageBar(frac float64, width int) string {
+	filled := int(frac*float64(width) + 0.5)
+	filled = min(max(filled, 0), width)
+	return strings.Repeat("█", filled) + strings.Repeat("░", width-filled)
+}
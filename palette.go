@@ -0,0 +1,280 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// paletteMaxResults caps the number of matches shown at once
+const paletteMaxResults = 10
+
+// paletteAction is a named command that can be run from the palette
+type paletteAction struct {
+	name string
+	keys string
+	run  func(m *model) tea.Cmd
+}
+
+type paletteState struct {
+	active  bool
+	input   textinput.Model
+	cursor  int
+	matches []paletteAction
+}
+
+func newPaletteState() paletteState {
+	ti := textinput.New()
+	ti.Prompt = "❯ "
+	ti.Placeholder = "Type a command..."
+	ti.CharLimit = 64
+	return paletteState{input: ti}
+}
+
+// paletteActions returns every action the palette can run, in display order
+func (m model) paletteActions() []paletteAction {
+	actions := []paletteAction{
+		{name: "Cycle theme", keys: bindingHint(m.keys.Theme), run: func(m *model) tea.Cmd { m.cycleTheme(); return nil }},
+	}
+
+	for i, theme := range themes {
+		idx := i
+		actions = append(actions, paletteAction{
+			name: "Switch theme: " + theme.Name,
+			run:  func(m *model) tea.Cmd { m.setTheme(idx); return nil },
+		})
+	}
+
+	actions = append(actions,
+		paletteAction{name: "Toggle sound wave panel", keys: bindingHint(m.keys.SoundWave), run: func(m *model) tea.Cmd { m.toggleSoundWave(); return nil }},
+		paletteAction{name: "Toggle zoom on focused panel", keys: bindingHint(m.keys.Zoom), run: func(m *model) tea.Cmd { m.toggleZoom(); return nil }},
+		paletteAction{name: "Open alert history", keys: bindingHint(m.keys.Alerts), run: func(m *model) tea.Cmd { m.showAlerts = true; return nil }},
+		paletteAction{name: "Run system scan", keys: bindingHint(m.keys.Scan), run: func(m *model) tea.Cmd { return m.startScan() }},
+		paletteAction{name: "Pause / resume", keys: bindingHint(m.keys.Pause), run: func(m *model) tea.Cmd { m.togglePause(); return nil }},
+		paletteAction{name: "Reboot system", keys: bindingHint(m.keys.Reboot), run: func(m *model) tea.Cmd { m.reboot(); return nil }},
+		paletteAction{name: "Show help", keys: bindingHint(m.keys.Help), run: func(m *model) tea.Cmd { m.showHelp = true; return nil }},
+		paletteAction{name: "Quit", keys: bindingHint(m.keys.Quit), run: func(m *model) tea.Cmd { return tea.Quit }},
+	)
+
+	for i, p := range pages {
+		idx := i
+		actions = append(actions, paletteAction{
+			name: "Go to page: " + p.name,
+			keys: bindingHint(m.keys.Pages[i]),
+			run:  func(m *model) tea.Cmd { m.setPage(idx); return nil },
+		})
+	}
+
+	for i, h := range m.hosts {
+		idx := i + 1
+		actions = append(actions, paletteAction{
+			name: "Open fleet host: " + h.Name,
+			run:  func(m *model) tea.Cmd { return m.openHost(idx) },
+		})
+	}
+	if m.remote != nil {
+		actions = append(actions, paletteAction{
+			name: "Return to local host",
+			run:  func(m *model) tea.Cmd { return m.openHost(0) },
+		})
+	}
+
+	for _, c := range m.containers {
+		for _, action := range []string{"start", "stop", "restart"} {
+			actions = append(actions, paletteAction{
+				name: fmt.Sprintf("Container %s: %s", action, c.Name),
+				run:  func(m *model) tea.Cmd { return m.containerActionFor(c, action) },
+			})
+		}
+		actions = append(actions, paletteAction{
+			name: "Follow container logs: " + c.Name,
+			run:  func(m *model) tea.Cmd { return m.toggleContainerLogsFor(c) },
+		})
+	}
+
+	for _, p := range m.processes {
+		pid := p.PID
+		actions = append(actions, paletteAction{
+			name: fmt.Sprintf("Jump to process: %s (%d)", p.Name, pid),
+			run:  func(m *model) tea.Cmd { m.jumpToProcess(pid); return nil },
+		})
+	}
+
+	return actions
+}
+
+// bindingHint returns the display keys for a binding, or nothing if it is unbound
+func bindingHint(b key.Binding) string {
+	if !b.Enabled() {
+		return ""
+	}
+	return b.Help().Key
+}
+
+// fuzzyScore reports whether every rune of pattern appears in target in order,
+// scoring consecutive runs and word starts higher
+func fuzzyScore(pattern, target string) (int, bool) {
+	p := []rune(strings.ToLower(pattern))
+	t := []rune(strings.ToLower(target))
+	if len(p) == 0 {
+		return 0, true
+	}
+
+	score := 0
+	pi := 0
+	prev := -2
+	for ti, r := range t {
+		if pi >= len(p) {
+			break
+		}
+		if r != p[pi] {
+			continue
+		}
+		score++
+		if ti == prev+1 {
+			score += 5
+		}
+		if ti == 0 || !unicode.IsLetter(t[ti-1]) {
+			score += 10
+		}
+		prev = ti
+		pi++
+	}
+	if pi < len(p) {
+		return 0, false
+	}
+
+	// Prefer shorter targets when matches are otherwise equal
+	return score*100 - len(t), true
+}
+
+// filterActions returns the actions matching query, best match first
+func filterActions(actions []paletteAction, query string) []paletteAction {
+	type scored struct {
+		action paletteAction
+		score  int
+	}
+
+	var results []scored
+	for _, a := range actions {
+		if s, ok := fuzzyScore(query, a.name); ok {
+			results = append(results, scored{a, s})
+		}
+	}
+
+	if query != "" {
+		sort.SliceStable(results, func(i, j int) bool { return results[i].score > results[j].score })
+	}
+
+	matches := make([]paletteAction, len(results))
+	for i, r := range results {
+		matches[i] = r.action
+	}
+	return matches
+}
+
+func (m *model) openPalette() tea.Cmd {
+	m.palette.active = true
+	m.palette.cursor = 0
+	m.palette.input.Reset()
+	m.palette.matches = filterActions(m.paletteActions(), "")
+	return m.palette.input.Focus()
+}
+
+func (m *model) closePalette() {
+	m.palette.active = false
+	m.palette.input.Blur()
+}
+
+func (m model) updatePalette(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// The palette owns the keyboard while open; only its toggle escapes
+	if key.Matches(msg, m.keys.Palette) {
+		m.closePalette()
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+
+	case "esc":
+		m.closePalette()
+		return m, nil
+
+	case "up":
+		if m.palette.cursor > 0 {
+			m.palette.cursor--
+		}
+		return m, nil
+
+	case "down":
+		if m.palette.cursor < len(m.palette.matches)-1 {
+			m.palette.cursor++
+		}
+		return m, nil
+
+	case "enter":
+		m.closePalette()
+		if m.palette.cursor < len(m.palette.matches) {
+			cmd := m.palette.matches[m.palette.cursor].run(&m)
+			return m, cmd
+		}
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.palette.input, cmd = m.palette.input.Update(msg)
+	m.palette.matches = filterActions(m.paletteActions(), m.palette.input.Value())
+	m.palette.cursor = 0
+	return m, cmd
+}
+
+func (m model) renderPalette() string {
+	theme := m.getTheme()
+	width := 50
+
+	boxStyle := lipgloss.NewStyle().
+		Border(lipgloss.DoubleBorder()).
+		BorderForeground(theme.Primary).
+		Padding(0, 1).
+		Background(theme.Background).
+		Width(width)
+
+	titleStyle := lipgloss.NewStyle().Foreground(theme.Accent).Bold(true)
+	itemStyle := lipgloss.NewStyle().Foreground(theme.Primary)
+	selectedStyle := lipgloss.NewStyle().Foreground(theme.Background).Background(theme.Primary).Bold(true)
+	keyStyle := lipgloss.NewStyle().Foreground(theme.Dim)
+
+	lines := []string{titleStyle.Render("COMMAND PALETTE"), m.palette.input.View(), ""}
+
+	if len(m.palette.matches) == 0 {
+		lines = append(lines, keyStyle.Render("No matching commands"))
+	}
+
+	// Scroll the result window so the cursor stays visible
+	start := 0
+	if m.palette.cursor >= paletteMaxResults {
+		start = m.palette.cursor - paletteMaxResults + 1
+	}
+	for i := start; i < len(m.palette.matches) && i < start+paletteMaxResults; i++ {
+		a := m.palette.matches[i]
+		gap := width - 2 - lipgloss.Width(a.name) - lipgloss.Width(a.keys)
+		if gap < 1 {
+			gap = 1
+		}
+		row := a.name + strings.Repeat(" ", gap) + a.keys
+		if i == m.palette.cursor {
+			lines = append(lines, selectedStyle.Render(row))
+		} else {
+			lines = append(lines, itemStyle.Render(a.name)+strings.Repeat(" ", gap)+keyStyle.Render(a.keys))
+		}
+	}
+
+	return boxStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
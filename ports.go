@@ -0,0 +1,203 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/mattn/go-runewidth"
+	gnet "github.com/shirou/gopsutil/v3/net"
+)
+
+// PortsConfig lists the services allowed to listen on every interface
+type PortsConfig struct {
+	// Allow entries are a port ("443"), a protocol and port ("udp/53") or a
+	// process name ("sshd")
+	Allow []string `json:"allow"`
+}
+
+// listenInfo is a listening TCP socket or a bound UDP socket
+type listenInfo struct {
+	Proto   string `json:"proto"`
+	Addr    string `json:"addr"`
+	Port    uint32 `json:"port"`
+	PID     int32  `json:"pid"`
+	Process string `json:"process"`
+}
+
+func (l listenInfo) key() string {
+	return l.Proto + " " + l.bind()
+}
+
+// bind is the bind address and port, e.g. 0.0.0.0:22 or [::]:22
+func (l listenInfo) bind() string {
+	return net.JoinHostPort(l.Addr, strconv.Itoa(int(l.Port)))
+}
+
+// exposed reports whether the socket accepts traffic on every interface
+func (l listenInfo) exposed() bool {
+	return l.Addr == "0.0.0.0" || l.Addr == "::" || l.Addr == "*"
+}
+
+// isListener reports whether a socket is waiting for peers rather than
+// talking to one
+func isListener(s gnet.ConnectionStat) bool {
+	if s.Laddr.Port == 0 {
+		return false
+	}
+	if s.Type == syscall.SOCK_DGRAM {
+		// An unconnected UDP socket reports an unspecified peer, e.g.
+		// 0.0.0.0:0 or [::]:0, not an empty one
+		return s.Raddr.Port == 0
+	}
+	return s.Status == "LISTEN"
+}
+
+// portAllowlist is a parsed PortsConfig
+type portAllowlist struct {
+	ports map[string]bool // "22" or "tcp/22"
+	names map[string]bool
+}
+
+func newPortAllowlist(cfg *PortsConfig) (portAllowlist, error) {
+	a := portAllowlist{ports: make(map[string]bool), names: make(map[string]bool)}
+	if cfg == nil {
+		return a, nil
+	}
+	for _, e := range cfg.Allow {
+		proto, port, ok := strings.Cut(e, "/")
+		if !ok {
+			port = e
+		}
+		if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+			if ok || e == "" {
+				return a, fmt.Errorf("ports: bad allow entry %q (want a port, tcp/PORT, udp/PORT or a process name)", e)
+			}
+			a.names[e] = true
+			continue
+		}
+		if ok && proto != "tcp" && proto != "udp" {
+			return a, fmt.Errorf("ports: bad protocol in allow entry %q (want tcp or udp)", e)
+		}
+		a.ports[e] = true
+	}
+	return a, nil
+}
+
+// allows reports whether a listener may be exposed. IPv4 and IPv6 sockets
+// match the same entries.
+func (a portAllowlist) allows(l listenInfo) bool {
+	port := strconv.Itoa(int(l.Port))
+	proto := strings.TrimSuffix(l.Proto, "6")
+	return a.names[l.Process] || a.ports[port] || a.ports[proto+"/"+port]
+}
+
+// flagged reports whether a listener is exposed without being allowlisted
+func (m model) flagged(l listenInfo) bool {
+	return l.exposed() && !m.portAllow.allows(l)
+}
+
+// --- Model glue ---
+
+// setListeners updates the ports table and raises an alert for each newly
+// exposed service that is not on the allowlist. A service alerts again only
+// after it has stopped listening.
+func (m *model) setListeners(listeners []listenInfo) {
+	// SO_REUSEPORT and forked servers share a bind; list it once
+	seen := make(map[string]bool, len(listeners))
+	var unique []listenInfo
+	for _, l := range listeners {
+		if !seen[l.key()] {
+			seen[l.key()] = true
+			unique = append(unique, l)
+		}
+	}
+	sort.Slice(unique, func(i, j int) bool {
+		if unique[i].Port != unique[j].Port {
+			return unique[i].Port < unique[j].Port
+		}
+		return unique[i].key() < unique[j].key()
+	})
+
+	alerted := make(map[string]bool)
+	rows := make([][]string, len(unique))
+	for i, l := range unique {
+		exposure := "LOCAL"
+		switch {
+		case m.flagged(l):
+			exposure = "⚠ EXPOSED"
+			if !m.exposedAlerted[l.key()] {
+				m.raiseAlert(fmt.Sprintf("EXPOSED SERVICE: %s on %s/%s", listenerName(l), l.bind(), l.Proto), 2)
+			}
+			alerted[l.key()] = true
+		case l.exposed():
+			exposure = "ALLOWED"
+		}
+		pid := "-"
+		if l.PID > 0 {
+			pid = strconv.Itoa(int(l.PID))
+		}
+		process := l.Process
+		if process == "" {
+			process = "-"
+		}
+		rows[i] = []string{l.Proto, l.Addr, strconv.Itoa(int(l.Port)), pid, process, exposure}
+	}
+
+	m.listeners = unique
+	m.exposedAlerted = alerted
+	m.portTable.setRows(rows)
+}
+
+func listenerName(l listenInfo) string {
+	if l.Process == "" {
+		return "unknown process"
+	}
+	return l.Process
+}
+
+// auditPorts reports the scan's findings: every exposed service that is not
+// on the allowlist
+func (m *model) auditPorts() {
+	if m.connErr != nil {
+		m.addLog(fmt.Sprintf("System scan incomplete: cannot read sockets: %v", m.connErr))
+		return
+	}
+	if len(m.listeners) == 0 {
+		// No socket data, e.g. while drilled into a fleet host
+		m.addLog("System scan complete: all systems nominal")
+		return
+	}
+	var flagged []listenInfo
+	for _, l := range m.listeners {
+		if m.flagged(l) {
+			flagged = append(flagged, l)
+		}
+	}
+	if len(flagged) == 0 {
+		m.addLog(fmt.Sprintf("System scan complete: %d listening ports, none exposed outside the allowlist", len(m.listeners)))
+		return
+	}
+	for _, l := range flagged {
+		m.addLog(fmt.Sprintf("Scan: %s listens on %s/%s", listenerName(l), l.bind(), l.Proto))
+	}
+	m.raiseAlert(fmt.Sprintf("SCAN: %d EXPOSED SERVICES NOT ALLOWLISTED", len(flagged)), 2)
+}
+
+func (m model) renderPortsPanel(width, height int) string {
+	exposed := 0
+	for _, l := range m.listeners {
+		if m.flagged(l) {
+			exposed++
+		}
+	}
+	title := fmt.Sprintf("LISTENING PORTS · %d EXPOSED", exposed)
+	if m.connErr != nil {
+		title += " · " + m.connErr.Error()
+	}
+	title = runewidth.Truncate(title, max(width-4, 1), "…")
+	return m.renderTablePanel(panelPorts, title, m.portTable, width, height)
+}
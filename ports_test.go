@@ -0,0 +1,138 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"syscall"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	gnet "github.com/shirou/gopsutil/v3/net"
+)
+
+func testListeners() []listenInfo {
+	return []listenInfo{
+		{Proto: "tcp", Addr: "0.0.0.0", Port: 22, PID: 812, Process: "sshd"},
+		{Proto: "tcp6", Addr: "::", Port: 22, PID: 812, Process: "sshd"},
+		{Proto: "tcp", Addr: "127.0.0.1", Port: 5432, PID: 977, Process: "postgres"},
+		{Proto: "tcp", Addr: "0.0.0.0", Port: 8080, PID: 1337, Process: "jarvis"},
+		{Proto: "tcp", Addr: "0.0.0.0", Port: 8080, PID: 1338, Process: "jarvis"},
+		{Proto: "udp", Addr: "0.0.0.0", Port: 5353},
+	}
+}
+
+func TestPortAllowlist(t *testing.T) {
+	a, err := newPortAllowlist(&PortsConfig{Allow: []string{"sshd", "udp/5353", "443"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		l    listenInfo
+		want bool
+	}{
+		{listenInfo{Proto: "tcp6", Port: 22, Process: "sshd"}, true},
+		{listenInfo{Proto: "udp6", Port: 5353}, true},
+		{listenInfo{Proto: "tcp", Port: 5353}, false},
+		{listenInfo{Proto: "tcp", Port: 443, Process: "nginx"}, true},
+		{listenInfo{Proto: "tcp", Port: 8080, Process: "jarvis"}, false},
+	}
+	for _, c := range cases {
+		if got := a.allows(c.l); got != c.want {
+			t.Errorf("allows(%+v) = %v, want %v", c.l, got, c.want)
+		}
+	}
+
+	for _, bad := range []string{"sctp/80", "tcp/http", "tcp/70000", ""} {
+		if _, err := newPortAllowlist(&PortsConfig{Allow: []string{bad}}); err == nil {
+			t.Errorf("allow entry %q accepted", bad)
+		}
+	}
+}
+
+func TestIsListener(t *testing.T) {
+	listen := gnet.ConnectionStat{Type: syscall.SOCK_STREAM, Status: "LISTEN", Laddr: gnet.Addr{IP: "0.0.0.0", Port: 22}}
+	established := gnet.ConnectionStat{Type: syscall.SOCK_STREAM, Status: "ESTABLISHED", Laddr: gnet.Addr{IP: "10.0.0.5", Port: 22}, Raddr: gnet.Addr{IP: "10.0.0.9", Port: 50000}}
+	bound := gnet.ConnectionStat{Type: syscall.SOCK_DGRAM, Laddr: gnet.Addr{IP: "0.0.0.0", Port: 5353}, Raddr: gnet.Addr{IP: "0.0.0.0"}}
+	bound6 := gnet.ConnectionStat{Type: syscall.SOCK_DGRAM, Laddr: gnet.Addr{IP: "::", Port: 5353}, Raddr: gnet.Addr{IP: "::"}}
+	connected := gnet.ConnectionStat{Type: syscall.SOCK_DGRAM, Laddr: gnet.Addr{IP: "10.0.0.5", Port: 41000}, Raddr: gnet.Addr{IP: "10.0.0.1", Port: 53}}
+	if !isListener(listen) || isListener(established) || !isListener(bound) || !isListener(bound6) || isListener(connected) {
+		t.Error("isListener misclassifies sockets")
+	}
+}
+
+func TestExposedServiceAlerts(t *testing.T) {
+	m := newTestModel(t, 120, 40)
+	if err := m.applyConfig(Config{Ports: &PortsConfig{Allow: []string{"sshd"}}}); err != nil {
+		t.Fatal(err)
+	}
+
+	m = step(m, connectionsMsg{listeners: testListeners()})
+	var got []string
+	for _, a := range m.alertHistory {
+		got = append(got, a.Message)
+	}
+	want := "EXPOSED SERVICE: unknown process on 0.0.0.0:5353/udp, EXPOSED SERVICE: jarvis on 0.0.0.0:8080/tcp"
+	if strings.Join(got, ", ") != want {
+		t.Errorf("alerts = %s\nwant     %s", strings.Join(got, ", "), want)
+	}
+	if len(m.listeners) != 5 {
+		t.Errorf("listeners = %d, want 5 after merging the shared bind", len(m.listeners))
+	}
+
+	// Still listening: no repeat. Gone and back: alert again.
+	m = step(m, connectionsMsg{listeners: testListeners()})
+	m = step(m, connectionsMsg{listeners: testListeners()[:3]})
+	m = step(m, connectionsMsg{listeners: testListeners()})
+	if n := len(m.alertHistory); n != 4 {
+		t.Errorf("alerts = %d, want 4", n)
+	}
+
+	// A failed read keeps the list, so recovering does not alert again
+	m = step(m, connectionsMsg{err: errors.New("permission denied")})
+	if len(m.listeners) != 5 {
+		t.Errorf("listeners = %d after a failed read, want 5", len(m.listeners))
+	}
+	m = step(m, connectionsMsg{listeners: testListeners()})
+	if n := len(m.alertHistory); n != 4 {
+		t.Errorf("alerts = %d after recovering, want 4", n)
+	}
+}
+
+func TestScanAuditsPorts(t *testing.T) {
+	m := newTestModel(t, 120, 40)
+	m = step(m, connectionsMsg{listeners: testListeners()[:3]})
+	if len(m.alertHistory) != 2 {
+		t.Fatalf("alerts before scan = %d", len(m.alertHistory))
+	}
+
+	m.startScan()
+	for m.systemScan {
+		m = step(m, tickMsg(testTime))
+	}
+	last := m.alertHistory[len(m.alertHistory)-1]
+	if last.Message != "SCAN: 2 EXPOSED SERVICES NOT ALLOWLISTED" {
+		t.Errorf("scan alert = %q", last.Message)
+	}
+	if !strings.Contains(strings.Join(m.logs, "\n"), "Scan: sshd listens on [::]:22/tcp6") {
+		t.Error("scan does not log the exposed services")
+	}
+
+	// A scan that cannot read sockets says so rather than reporting clean
+	m = step(m, connectionsMsg{err: errors.New("permission denied")})
+	m.startScan()
+	for m.systemScan {
+		m = step(m, tickMsg(testTime))
+	}
+	logs := strings.Join(m.logs, "\n")
+	if !strings.Contains(logs, "System scan incomplete: cannot read sockets: permission denied") {
+		t.Errorf("scan with a failed socket read logged:\n%s", logs)
+	}
+}
+
+func TestPortsPageKey(t *testing.T) {
+	m := newTestModel(t, 120, 40)
+	m = step(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("o")})
+	if m.page != pagePorts {
+		t.Errorf("o opened page %d, want PORTS", m.page)
+	}
+}
@@ -0,0 +1,280 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// PowerConfig configures the power panel and what the THRUSTER POWER bar shows
+type PowerConfig struct {
+	Root       string  `json:"root"`        // default /sys/class/power_supply
+	Thruster   string  `json:"thruster"`    // "memory" (default) or "battery"
+	LowBattery float64 `json:"low_battery"` // alert threshold in percent, default 15
+}
+
+const (
+	defaultPowerRoot  = "/sys/class/power_supply"
+	defaultLowBattery = 15.0
+	// criticalBattery escalates the low battery alert
+	criticalBattery = 5.0
+)
+
+// batteryInfo is one battery. Energy is in Wh; Remaining is zero when the
+// driver doesn't report a rate to estimate it from.
+type batteryInfo struct {
+	Name       string        `json:"name"`
+	Percent    float64       `json:"percent"`
+	Status     string        `json:"status"` // Charging, Discharging, Full, Not charging, Unknown
+	EnergyNow  float64       `json:"energy_now,omitempty"`
+	EnergyFull float64       `json:"energy_full,omitempty"`
+	Remaining  time.Duration `json:"remaining,omitempty"`
+}
+
+type powerMsg struct {
+	batteries []batteryInfo
+	hasAC     bool // a mains or USB supply exists
+	acOnline  bool
+	err       error
+}
+
+// charge is the combined battery level, 0-1, weighting batteries by
+// capacity when every one of them reports it
+func (p powerMsg) charge() (float64, bool) {
+	if len(p.batteries) == 0 {
+		return 0, false
+	}
+	var now, full, percent float64
+	weighted := true
+	for _, b := range p.batteries {
+		now += b.EnergyNow
+		full += b.EnergyFull
+		percent += b.Percent
+		weighted = weighted && b.EnergyFull > 0
+	}
+	if weighted {
+		return min(now/full, 1), true
+	}
+	return percent / float64(len(p.batteries)) / 100, true
+}
+
+// discharging reports whether the machine is running down its batteries
+func (p powerMsg) discharging() bool {
+	for _, b := range p.batteries {
+		if b.Status == "Discharging" {
+			return true
+		}
+	}
+	return p.hasAC && !p.acOnline && len(p.batteries) > 0
+}
+
+// powerReader reads batteries and AC adapters from sysfs
+type powerReader struct {
+	root string
+}
+
+func newPowerReader(cfg *PowerConfig) *powerReader {
+	r := &powerReader{root: defaultPowerRoot}
+	if cfg != nil && cfg.Root != "" {
+		r.root = cfg.Root
+	}
+	return r
+}
+
+// thrusterBattery reports whether THRUSTER POWER shows battery charge
+func (c *PowerConfig) thrusterBattery() (bool, error) {
+	if c == nil {
+		return false, nil
+	}
+	switch c.Thruster {
+	case "", "memory":
+		return false, nil
+	case "battery":
+		return true, nil
+	}
+	return false, fmt.Errorf("power: unknown thruster binding %q (want \"memory\" or \"battery\")", c.Thruster)
+}
+
+func (c *PowerConfig) lowBattery() float64 {
+	if c == nil || c.LowBattery <= 0 {
+		return defaultLowBattery
+	}
+	return c.LowBattery
+}
+
+func (r *powerReader) read() powerMsg {
+	var msg powerMsg
+	entries, err := os.ReadDir(r.root)
+	if err != nil {
+		msg.err = err
+		return msg
+	}
+	for _, e := range entries {
+		dir := filepath.Join(r.root, e.Name())
+		typ, _ := readSysfsString(filepath.Join(dir, "type"))
+		switch typ {
+		case "Battery":
+			// Peripherals (mice, headsets) report scope Device; only system batteries count
+			if scope, _ := readSysfsString(filepath.Join(dir, "scope")); scope == "Device" {
+				continue
+			}
+			if present, ok := readSysfsInt(filepath.Join(dir, "present")); ok && present == 0 {
+				continue
+			}
+			msg.batteries = append(msg.batteries, readBattery(dir, e.Name()))
+		case "Mains", "USB", "USB_C", "USB_PD":
+			msg.hasAC = true
+			if online, _ := readSysfsInt(filepath.Join(dir, "online")); online == 1 {
+				msg.acOnline = true
+			}
+		}
+	}
+	return msg
+}
+
+// readBattery reads a battery that reports either energy (µWh, µW) or
+// charge (µAh, µA) counters; the time estimate works the same for both
+func readBattery(dir, name string) batteryInfo {
+	b := batteryInfo{Name: name, Status: "Unknown"}
+	if s, ok := readSysfsString(filepath.Join(dir, "status")); ok {
+		b.Status = s
+	}
+
+	read := func(file string) float64 {
+		v, _ := readSysfsInt(filepath.Join(dir, file))
+		return float64(max(v, 0))
+	}
+	now, full, rate := read("energy_now"), read("energy_full"), read("power_now")
+	if full == 0 {
+		now, full, rate = read("charge_now"), read("charge_full"), read("current_now")
+		// Convert charge to energy with the nominal voltage when it is known
+		if volts := read("voltage_min_design") / 1e6; volts > 0 {
+			b.EnergyNow, b.EnergyFull = now*volts/1e6, full*volts/1e6
+		}
+	} else {
+		b.EnergyNow, b.EnergyFull = now/1e6, full/1e6
+	}
+
+	if pct, ok := readSysfsInt(filepath.Join(dir, "capacity")); ok {
+		b.Percent = float64(pct)
+	} else if full > 0 {
+		b.Percent = now / full * 100
+	}
+
+	if rate > 0 {
+		hours := 0.0
+		switch b.Status {
+		case "Discharging":
+			hours = now / rate
+		case "Charging":
+			hours = max(full-now, 0) / rate
+		}
+		b.Remaining = time.Duration(hours * float64(time.Hour)).Round(time.Minute)
+	}
+	return b
+}
+
+func collectPowerCommand(r *powerReader) tea.Cmd {
+	return func() tea.Msg {
+		return r.read()
+	}
+}
+
+// --- Model glue ---
+
+func (m *model) setPower(msg powerMsg) {
+	m.power = msg
+	charge, ok := msg.charge()
+	if ok && m.thrusterBattery {
+		m.pwrVal = charge
+	}
+	m.checkBattery(charge*100, ok && msg.discharging())
+}
+
+// checkBattery raises an alert when a discharging battery crosses the low
+// or critical level. Each level fires once until the battery recovers.
+func (m *model) checkBattery(percent float64, discharging bool) {
+	level := 0
+	switch {
+	case !discharging:
+	case percent <= criticalBattery:
+		level = 3
+	case percent <= m.lowBattery:
+		level = 2
+	}
+	if level > m.batteryAlert {
+		if level == 3 {
+			m.raiseAlert(fmt.Sprintf("BATTERY CRITICAL: %.0f%%", percent), level)
+		} else {
+			m.raiseAlert(fmt.Sprintf("BATTERY LOW: %.0f%%", percent), level)
+		}
+	}
+	// Re-arm once plugged in, or when the charge is back above the threshold
+	if level > m.batteryAlert || !discharging || percent > m.lowBattery {
+		m.batteryAlert = level
+	}
+}
+
+func (m model) renderPowerPanel(width, height int) string {
+	theme := m.getTheme()
+	label := lipgloss.NewStyle().Foreground(theme.Dim)
+	value := lipgloss.NewStyle().Foreground(theme.Primary).Bold(true)
+
+	lines := []string{headerStyle.Render("POWER SUPPLY"), ""}
+	switch {
+	case m.power.err != nil && !errors.Is(m.power.err, os.ErrNotExist):
+		lines = append(lines, lipgloss.NewStyle().Foreground(alertRed).Render(m.power.err.Error()))
+	case !m.power.hasAC && len(m.power.batteries) == 0:
+		// Desktops and most VMs have no power_supply entries at all
+		lines = append(lines, label.Render("No power supplies reported"))
+	}
+
+	if m.power.hasAC {
+		ac := lipgloss.NewStyle().Foreground(alertGreen).Bold(true).Render("ONLINE")
+		if !m.power.acOnline {
+			ac = lipgloss.NewStyle().Foreground(alertYellow).Bold(true).Render("ON BATTERY")
+		}
+		lines = append(lines, label.Render("AC POWER  ")+ac, "")
+	}
+
+	barWidth := max(width-16, 5)
+	for _, b := range m.power.batteries {
+		color := alertGreen
+		switch {
+		case b.Percent <= criticalBattery:
+			color = alertRed
+		case b.Percent <= m.lowBattery:
+			color = alertYellow
+		}
+		lines = append(lines,
+			value.Render(strings.ToUpper(b.Name))+label.Render("  "+strings.ToUpper(b.Status)),
+			lipgloss.NewStyle().Foreground(color).Render(usageBar(b.Percent/100, barWidth))+value.Render(fmt.Sprintf(" %3.0f%%", b.Percent)),
+			label.Render(batteryEstimate(b)),
+			"",
+		)
+	}
+
+	return m.panelStyle(panelPower).Width(width).Height(height).
+		Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
+
+// batteryEstimate describes the time to empty or full
+func batteryEstimate(b batteryInfo) string {
+	if b.Status == "Full" {
+		return "Fully charged"
+	}
+	if b.Remaining <= 0 {
+		return "Time remaining: unknown"
+	}
+	d := fmt.Sprintf("%dh %02dm", int(b.Remaining.Hours()), int(b.Remaining.Minutes())%60)
+	if b.Status == "Charging" {
+		return "Full in " + d
+	}
+	return "Time remaining: " + d
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func testPower() powerMsg {
+	return powerMsg{
+		batteries: []batteryInfo{{Name: "BAT0", Percent: 12, Status: "Discharging", EnergyNow: 6, EnergyFull: 50, Remaining: 48 * time.Minute}},
+		hasAC:     true,
+	}
+}
+
+func TestPowerReader(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"AC/type":   "Mains",
+		"AC/online": "0",
+
+		// Energy counters: 30 of 50 Wh left at 10 W is three hours
+		"BAT0/type":        "Battery",
+		"BAT0/status":      "Discharging",
+		"BAT0/present":     "1",
+		"BAT0/capacity":    "60",
+		"BAT0/energy_now":  "30000000",
+		"BAT0/energy_full": "50000000",
+		"BAT0/power_now":   "10000000",
+
+		// Charge counters: 1 of 4 Ah missing at 2 A is half an hour
+		"BAT1/type":               "Battery",
+		"BAT1/status":             "Charging",
+		"BAT1/charge_now":         "3000000",
+		"BAT1/charge_full":        "4000000",
+		"BAT1/current_now":        "2000000",
+		"BAT1/voltage_min_design": "12500000",
+
+		"hid-mouse-battery/type":     "Battery",
+		"hid-mouse-battery/scope":    "Device",
+		"hid-mouse-battery/capacity": "5",
+	})
+
+	msg := newPowerReader(&PowerConfig{Root: root}).read()
+	if msg.err != nil {
+		t.Fatal(msg.err)
+	}
+	if !msg.hasAC || msg.acOnline || len(msg.batteries) != 2 {
+		t.Fatalf("read = %+v", msg)
+	}
+	bat0, bat1 := msg.batteries[0], msg.batteries[1]
+	if bat0.Percent != 60 || bat0.Remaining != 3*time.Hour || bat0.EnergyFull != 50 {
+		t.Errorf("BAT0 = %+v", bat0)
+	}
+	if bat1.Percent != 75 || bat1.Remaining != 30*time.Minute || bat1.EnergyFull != 50 {
+		t.Errorf("BAT1 = %+v", bat1)
+	}
+	if got := batteryEstimate(bat1); got != "Full in 0h 30m" {
+		t.Errorf("BAT1 estimate = %q", got)
+	}
+
+	// Both hold 50 Wh, so the combined charge is (30+37.5)/100
+	if c, ok := msg.charge(); !ok || c != 0.675 {
+		t.Errorf("charge = %v, %v", c, ok)
+	}
+	if !msg.discharging() {
+		t.Error("unplugged machine is not discharging")
+	}
+}
+
+func TestLowBatteryAlert(t *testing.T) {
+	m := newTestModel(t, 120, 40)
+	if err := m.applyConfig(Config{Power: &PowerConfig{Root: t.TempDir(), Thruster: "battery", LowBattery: 20}}); err != nil {
+		t.Fatal(err)
+	}
+
+	battery := func(percent float64, status string) powerMsg {
+		return powerMsg{batteries: []batteryInfo{{Name: "BAT0", Percent: percent, Status: status}}}
+	}
+
+	m = step(m, battery(25, "Discharging"))
+	m = step(m, sampleMsg{sample: Sample{MemUsedPercent: 63.5}})
+	if m.alertActive || m.pwrVal != 0.25 {
+		t.Errorf("at 25%%: alert=%v thruster=%v", m.alertActive, m.pwrVal)
+	}
+	// The fleet's LOCAL card still shows memory, not the battery
+	if card := ansi.Strip(m.renderLocalCard()); !strings.Contains(card, "64%") || strings.Contains(card, "25%") {
+		t.Errorf("LOCAL card with a battery thruster:\n%s", card)
+	}
+
+	m = step(m, battery(19, "Discharging"))
+	m = step(m, battery(18, "Discharging"))
+	if n := len(m.alertHistory); n != 1 || m.alertHistory[0].Message != "BATTERY LOW: 19%" || m.alertSeverity != 2 {
+		t.Fatalf("after crossing 20%%: %+v", m.alertHistory)
+	}
+
+	m = step(m, battery(4, "Discharging"))
+	if n := len(m.alertHistory); n != 2 || m.alertSeverity != 3 {
+		t.Errorf("critical level did not escalate: %+v", m.alertHistory)
+	}
+
+	// Plugging in re-arms the alert for the next discharge
+	m = step(m, battery(5, "Charging"))
+	m = step(m, battery(5, "Discharging"))
+	if n := len(m.alertHistory); n != 3 {
+		t.Errorf("alert was not re-armed: %+v", m.alertHistory)
+	}
+
+	if err := m.applyConfig(Config{Power: &PowerConfig{Thruster: "fuel"}}); err == nil || !strings.Contains(err.Error(), "thruster") {
+		t.Errorf("bad thruster binding: err = %v", err)
+	}
+}
+
+func TestPowerPanelWithoutSupplies(t *testing.T) {
+	m := newTestModel(t, 120, 40)
+	m = step(m, newPowerReader(&PowerConfig{Root: filepath.Join(t.TempDir(), "missing")}).read())
+	m.setPage(pageSensors)
+	if !strings.Contains(m.View(), "No power supplies reported") {
+		t.Error("missing power_supply tree should read as no supplies")
+	}
+}
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PrometheusConfig points the vitals panel at a Prometheus-compatible HTTP
+// API. Each query must evaluate to a single series or a scalar.
+type PrometheusConfig struct {
+	URL         string            `json:"url"`
+	Timeout     Duration          `json:"timeout"`
+	BearerToken string            `json:"bearer_token"`
+	Queries     map[string]string `json:"queries"` // overrides by metric name
+}
+
+// Metric names accepted in PrometheusConfig.Queries
+const (
+	promCPU     = "cpu"      // percent, 0-100
+	promMemory  = "memory"   // percent, 0-100
+	promNetSent = "net_sent" // cumulative bytes
+	promNetRecv = "net_recv" // cumulative bytes
+)
+
+// defaultPromQueries read node_exporter metrics aggregated across every
+// scraped node, so the bars show cluster-level load
+var defaultPromQueries = map[string]string{
+	promCPU:     `100 * (1 - avg(rate(node_cpu_seconds_total{mode="idle"}[1m])))`,
+	promMemory:  `100 * (1 - sum(node_memory_MemAvailable_bytes) / sum(node_memory_MemTotal_bytes))`,
+	promNetSent: `sum(node_network_transmit_bytes_total{device!="lo"})`,
+	promNetRecv: `sum(node_network_receive_bytes_total{device!="lo"})`,
+}
+
+const defaultPromTimeout = 5 * time.Second
+
+// prometheusSource is a MetricSource backed by PromQL queries
+type prometheusSource struct {
+	base    *url.URL
+	client  *http.Client
+	token   string
+	queries map[string]string
+	now     func() time.Time
+}
+
+func newPrometheusSource(cfg PrometheusConfig) (*prometheusSource, error) {
+	if cfg.URL == "" {
+		return nil, errors.New("prometheus source: url is required")
+	}
+	base, err := url.Parse(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("prometheus source: %w", err)
+	}
+	if base.Scheme != "http" && base.Scheme != "https" {
+		return nil, fmt.Errorf("prometheus source: url must be http or https, got %q", cfg.URL)
+	}
+
+	queries := make(map[string]string, len(defaultPromQueries))
+	for name, q := range defaultPromQueries {
+		queries[name] = q
+	}
+	for name, q := range cfg.Queries {
+		if _, ok := defaultPromQueries[name]; !ok {
+			return nil, fmt.Errorf("prometheus source: unknown query %q (want cpu, memory, net_sent or net_recv)", name)
+		}
+		queries[name] = q
+	}
+
+	timeout := time.Duration(cfg.Timeout)
+	if timeout <= 0 {
+		timeout = defaultPromTimeout
+	}
+
+	return &prometheusSource{
+		base:    base,
+		client:  &http.Client{Timeout: timeout},
+		token:   cfg.BearerToken,
+		queries: queries,
+		now:     time.Now,
+	}, nil
+}
+
+func (p *prometheusSource) Sample() (Sample, error) {
+	var s Sample
+	at := p.now()
+
+	// Run the queries in parallel so a sample costs one round trip
+	type result struct {
+		name  string
+		value float64
+		err   error
+	}
+	results := make(chan result, len(p.queries))
+	for name, q := range p.queries {
+		go func() {
+			v, err := p.query(q, at)
+			results <- result{name, v, err}
+		}()
+	}
+
+	values := make(map[string]float64, len(p.queries))
+	var errs []error
+	for range p.queries {
+		r := <-results
+		if r.err != nil {
+			errs = append(errs, fmt.Errorf("%s query: %w", r.name, r.err))
+		}
+		values[r.name] = r.value
+	}
+	if len(errs) > 0 {
+		return s, errors.Join(errs...)
+	}
+
+	s.CPUPercent = values[promCPU]
+	s.MemUsedPercent = values[promMemory]
+	s.NetBytesSent = uint64(max(values[promNetSent], 0))
+	s.NetBytesRecv = uint64(max(values[promNetRecv], 0))
+	return s, nil
+}
+
+// History evaluates a metric's query over the trailing window
+func (p *prometheusSource) History(metric string, window, step time.Duration) ([]float64, error) {
+	q, ok := p.queries[metric]
+	if !ok {
+		return nil, fmt.Errorf("unknown metric %q", metric)
+	}
+	end := p.now()
+	return p.queryRange(q, end.Add(-window), end, step)
+}
+
+// promResponse is the envelope of every Prometheus HTTP API reply
+type promResponse struct {
+	Status    string `json:"status"`
+	ErrorType string `json:"errorType"`
+	Error     string `json:"error"`
+	Data      struct {
+		ResultType string          `json:"resultType"`
+		Result     json.RawMessage `json:"result"`
+	} `json:"data"`
+}
+
+// promSeries is one series of a vector or matrix result
+type promSeries struct {
+	Metric map[string]string `json:"metric"`
+	Value  promPoint         `json:"value"`  // vector
+	Values []promPoint       `json:"values"` // matrix
+}
+
+// promPoint is a [unix_seconds, "value"] pair
+type promPoint [2]any
+
+func (pt promPoint) float() (float64, error) {
+	s, ok := pt[1].(string)
+	if !ok {
+		return 0, fmt.Errorf("malformed sample value %v", pt[1])
+	}
+	return strconv.ParseFloat(s, 64)
+}
+
+// query runs an instant query that must yield one value
+func (p *prometheusSource) query(q string, at time.Time) (float64, error) {
+	params := url.Values{"query": {q}, "time": {promTime(at)}}
+	resp, err := p.get("/api/v1/query", params)
+	if err != nil {
+		return 0, err
+	}
+
+	switch resp.Data.ResultType {
+	case "scalar":
+		var pt promPoint
+		if err := json.Unmarshal(resp.Data.Result, &pt); err != nil {
+			return 0, err
+		}
+		return pt.float()
+	case "vector":
+		var series []promSeries
+		if err := json.Unmarshal(resp.Data.Result, &series); err != nil {
+			return 0, err
+		}
+		if len(series) != 1 {
+			return 0, fmt.Errorf("got %d series, want 1 (aggregate with sum/avg)", len(series))
+		}
+		return series[0].Value.float()
+	}
+	return 0, fmt.Errorf("unsupported result type %q", resp.Data.ResultType)
+}
+
+// queryRange runs a range query that must yield one series
+func (p *prometheusSource) queryRange(q string, start, end time.Time, step time.Duration) ([]float64, error) {
+	params := url.Values{
+		"query": {q},
+		"start": {promTime(start)},
+		"end":   {promTime(end)},
+		"step":  {strconv.FormatFloat(step.Seconds(), 'f', -1, 64)},
+	}
+	resp, err := p.get("/api/v1/query_range", params)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Data.ResultType != "matrix" {
+		return nil, fmt.Errorf("unsupported result type %q", resp.Data.ResultType)
+	}
+
+	var series []promSeries
+	if err := json.Unmarshal(resp.Data.Result, &series); err != nil {
+		return nil, err
+	}
+	if len(series) != 1 {
+		return nil, fmt.Errorf("got %d series, want 1 (aggregate with sum/avg)", len(series))
+	}
+	values := make([]float64, len(series[0].Values))
+	for i, pt := range series[0].Values {
+		if values[i], err = pt.float(); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// get calls an API endpoint and decodes the response envelope
+func (p *prometheusSource) get(path string, params url.Values) (*promResponse, error) {
+	u := p.base.JoinPath(path)
+	u.RawQuery = params.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if p.token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.token)
+	}
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, 8<<20))
+	if err != nil {
+		return nil, err
+	}
+
+	var resp promResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		if res.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("%s: %s", res.Status, strings.TrimSpace(string(body)))
+		}
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+	if resp.Status != "success" {
+		return nil, fmt.Errorf("%s: %s", resp.ErrorType, resp.Error)
+	}
+	return &resp, nil
+}
+
+// promTime formats t as the API's unix-seconds timestamp
+func promTime(t time.Time) string {
+	return strconv.FormatFloat(float64(t.UnixMilli())/1000, 'f', 3, 64)
+}
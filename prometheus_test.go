@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakePrometheus answers instant and range queries with canned results
+// keyed by the PromQL expression
+func fakePrometheus(t *testing.T, instant, ranges map[string]string) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, "unauthorized")
+			return
+		}
+		q := r.URL.Query().Get("query")
+		var body string
+		var ok bool
+		switch r.URL.Path {
+		case "/prom/api/v1/query":
+			body, ok = instant[q]
+		case "/prom/api/v1/query_range":
+			body, ok = ranges[q]
+		}
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, `{"status":"error","errorType":"bad_data","error":"unknown query %s"}`, q)
+			return
+		}
+		fmt.Fprint(w, body)
+	}))
+}
+
+func vector(v string) string {
+	return `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1741944413,"` + v + `"]}]}}`
+}
+
+func TestPrometheusSourceSample(t *testing.T) {
+	srv := fakePrometheus(t, map[string]string{
+		"cluster_cpu":  vector("37.5"),
+		"cluster_mem":  `{"status":"success","data":{"resultType":"scalar","result":[1741944413,"81.25"]}}`,
+		"cluster_tx":   vector("1.5e9"),
+		"cluster_rx":   vector("3000"),
+		"two_series":   `{"status":"success","data":{"resultType":"vector","result":[{"metric":{"a":"1"},"value":[1,"1"]},{"metric":{"a":"2"},"value":[1,"2"]}]}}`,
+		"empty_vector": `{"status":"success","data":{"resultType":"vector","result":[]}}`,
+	}, nil)
+	defer srv.Close()
+
+	cfg := PrometheusConfig{
+		URL:         srv.URL + "/prom",
+		BearerToken: "s3cret",
+		Queries: map[string]string{
+			"cpu":      "cluster_cpu",
+			"memory":   "cluster_mem",
+			"net_sent": "cluster_tx",
+			"net_recv": "cluster_rx",
+		},
+	}
+	src, err := newPrometheusSource(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := src.Sample()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Sample{CPUPercent: 37.5, MemUsedPercent: 81.25, NetBytesSent: 1_500_000_000, NetBytesRecv: 3000}
+	if got != want {
+		t.Errorf("Sample() = %+v, want %+v", got, want)
+	}
+
+	for _, tc := range []struct {
+		query, err string
+	}{
+		{"two_series", "got 2 series"},
+		{"empty_vector", "got 0 series"},
+		{"missing", "bad_data: unknown query missing"},
+	} {
+		cfg.Queries["cpu"] = tc.query
+		src, _ := newPrometheusSource(cfg)
+		if _, err := src.Sample(); err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("query %s: err = %v, want %q", tc.query, err, tc.err)
+		}
+	}
+
+	cfg.BearerToken = ""
+	src, _ = newPrometheusSource(cfg)
+	if _, err := src.Sample(); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("without token: err = %v, want 401", err)
+	}
+}
+
+func TestPrometheusSourceHistory(t *testing.T) {
+	srv := fakePrometheus(t, nil, map[string]string{
+		"cluster_cpu": `{"status":"success","data":{"resultType":"matrix","result":[{"metric":{},"values":[[1,"10"],[2,"20"],[3,"30.5"]]}]}}`,
+	})
+	defer srv.Close()
+
+	src, err := newPrometheusSource(PrometheusConfig{
+		URL:         srv.URL + "/prom/",
+		BearerToken: "s3cret",
+		Queries:     map[string]string{"cpu": "cluster_cpu"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	src.now = func() time.Time { return testTime }
+
+	got, err := src.History("cpu", time.Minute, 15*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []float64{10, 20, 30.5}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("History() = %v, want %v", got, want)
+	}
+
+	if _, err := src.History("disk", time.Minute, time.Second); err == nil {
+		t.Error("History accepted an unknown metric")
+	}
+}
+
+func TestPrometheusSourceConfig(t *testing.T) {
+	for _, tc := range []struct {
+		cfg PrometheusConfig
+		err string
+	}{
+		{PrometheusConfig{}, "url is required"},
+		{PrometheusConfig{URL: "ftp://prom"}, "must be http or https"},
+		{PrometheusConfig{URL: "http://prom", Queries: map[string]string{"gpu": "x"}}, `unknown query "gpu"`},
+	} {
+		if _, err := newPrometheusSource(tc.cfg); err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("%+v: err = %v, want %q", tc.cfg, err, tc.err)
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// replayModel plays a session recording through a dashboard model. The
+// inner model's commands are dropped so nothing is collected live; it sees
+// only recorded messages, with its clock pinned to the recorded time.
+type replayModel struct {
+	inner  model
+	reader *sessionReader
+	keys   replayKeyMap
+
+	clock   *time.Time // read by inner.now
+	pending *sessionEvent
+	played  int
+	speed   float64
+	paused  bool
+	done    bool
+	err     error
+	gen     int // bumped on pause or speed changes to drop stale steps
+}
+
+type replayKeyMap struct {
+	Quit   key.Binding
+	Pause  key.Binding
+	Step   key.Binding
+	Faster key.Binding
+	Slower key.Binding
+}
+
+func defaultReplayKeyMap() replayKeyMap {
+	return replayKeyMap{
+		Quit:   newBinding("Quit", "q", "ctrl+c"),
+		Pause:  newBinding("Pause / resume", " "),
+		Step:   newBinding("Step one event", "n", "right"),
+		Faster: key.NewBinding(key.WithKeys("+", "="), key.WithHelp("+", "Faster")),
+		Slower: newBinding("Slower", "-"),
+	}
+}
+
+// replayStepMsg fires when the pending event is due
+type replayStepMsg struct{ gen int }
+
+const (
+	minReplaySpeed = 0.25
+	maxReplaySpeed = 256
+)
+
+func newReplayModel(m model, sr *sessionReader, speed float64, paused bool) replayModel {
+	clock := sr.header.Start
+	r := replayModel{
+		inner:  m,
+		reader: sr,
+		keys:   defaultReplayKeyMap(),
+		clock:  &clock,
+		speed:  min(max(speed, minReplaySpeed), maxReplaySpeed),
+		paused: paused,
+	}
+	r.inner.now = func() time.Time { return clock }
+	r.advance()
+	return r
+}
+
+func (r replayModel) Init() tea.Cmd {
+	// Init draws from the RNG, so run it as the live session did
+	r.inner.Init()
+	return r.schedule()
+}
+
+// advance reads the next event into pending
+func (r *replayModel) advance() {
+	ev, err := r.reader.next()
+	if err != nil {
+		r.pending = nil
+		r.done = true
+		if !errors.Is(err, io.EOF) {
+			r.err = err
+		}
+		return
+	}
+	r.pending = &ev
+}
+
+// schedule waits until the pending event is due at the current speed
+func (r replayModel) schedule() tea.Cmd {
+	if r.paused || r.done {
+		return nil
+	}
+	gen := r.gen
+	due := r.eventTime(*r.pending).Sub(*r.clock)
+	return tea.Tick(time.Duration(float64(due)/r.speed), func(time.Time) tea.Msg {
+		return replayStepMsg{gen: gen}
+	})
+}
+
+func (r replayModel) eventTime(ev sessionEvent) time.Time {
+	return r.reader.header.Start.Add(time.Duration(ev.T) * time.Millisecond)
+}
+
+// play feeds the pending event to the dashboard
+func (r *replayModel) play() {
+	if r.done {
+		return
+	}
+	*r.clock = r.eventTime(*r.pending)
+	msg, err := decodeMsg(*r.pending, *r.clock, r.inner)
+	if err != nil {
+		r.err, r.done = err, true
+		return
+	}
+	tm, _ := r.inner.Update(msg)
+	r.inner = tm.(model)
+	r.played++
+	r.advance()
+}
+
+func (r replayModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg := msg.(type) {
+	case replayStepMsg:
+		if msg.gen != r.gen || r.paused {
+			return r, nil
+		}
+		r.play()
+		cmd = r.schedule()
+		return r, cmd
+
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, r.keys.Quit):
+			return r, tea.Quit
+		case key.Matches(msg, r.keys.Pause):
+			r.paused = !r.paused
+			r.gen++
+			cmd = r.schedule()
+		case key.Matches(msg, r.keys.Step):
+			r.paused = true
+			r.play()
+		case key.Matches(msg, r.keys.Faster):
+			r.speed = min(r.speed*2, maxReplaySpeed)
+			r.gen++
+			cmd = r.schedule()
+		case key.Matches(msg, r.keys.Slower):
+			r.speed = max(r.speed/2, minReplaySpeed)
+			r.gen++
+			cmd = r.schedule()
+		}
+		return r, cmd
+	}
+
+	// Live resizes and mouse input are ignored: the recording carries its own
+	return r, nil
+}
+
+func (r replayModel) View() string {
+	frame := r.inner.View()
+
+	// The dashboard leaves its bottom row empty; the status line goes there
+	lines := strings.Split(frame, "\n")
+	status := r.renderStatus()
+	if r.inner.height > 0 && len(lines) >= r.inner.height {
+		lines[len(lines)-1] = status
+	} else {
+		lines = append(lines, status)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (r replayModel) renderStatus() string {
+	theme := r.inner.getTheme()
+	badge := lipgloss.NewStyle().Foreground(theme.Background).Background(theme.Primary).Bold(true).Padding(0, 1)
+	dim := lipgloss.NewStyle().Foreground(theme.Dim)
+
+	state := fmt.Sprintf("▶ %gx", r.speed)
+	switch {
+	case r.err != nil:
+		state = "ERROR: " + r.err.Error()
+	case r.done:
+		state = "■ END"
+	case r.paused:
+		state = "❚❚ PAUSED"
+	}
+
+	elapsed := r.clock.Sub(r.reader.header.Start).Truncate(time.Second)
+	info := fmt.Sprintf(" %s  %s  +%s  event %d ", state, r.clock.Format(time.DateTime), elapsed, r.played)
+	help := dim.Render(fmt.Sprintf("%s pause · %s step · %s/%s speed · %s quit",
+		bindingHint(r.keys.Pause), bindingHint(r.keys.Step),
+		bindingHint(r.keys.Faster), bindingHint(r.keys.Slower), bindingHint(r.keys.Quit)))
+
+	return lipgloss.NewStyle().MaxWidth(max(r.inner.width, 1)).
+		Render(badge.Render("REPLAY") + info + help)
+}
+
+// runReplay plays a recording in the terminal until the user quits. The
+// model is rebuilt from the recorded seed so random effects match.
+func runReplay(m model, path string, speed float64, step bool) error {
+	sr, f, err := openSession(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	m.rng = rand.New(rand.NewSource(sr.header.Seed))
+	p := tea.NewProgram(newReplayModel(m, sr, speed, step), tea.WithAltScreen())
+	_, err = p.Run()
+	return err
+}
@@ -0,0 +1,335 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/shirou/gopsutil/v3/disk"
+)
+
+// ResonanceConfig binds the resonance strip under the arc reactor to a metric
+type ResonanceConfig struct {
+	Metric string  `json:"metric"` // see resonanceMetrics; default "cpu"
+	Scale  string  `json:"scale"`  // "linear" (default) or "log"
+	Max    float64 `json:"max"`    // value at the top of the strip; default per metric
+}
+
+const (
+	// resonanceLen is how many samples the strip shows, oldest first
+	resonanceLen = 20
+	// resonanceStep is the time between samples: one collection round
+	resonanceStep = 2 * time.Second
+)
+
+// resonanceMetric describes a bindable metric. A zero max autoscales to
+// the largest value on the strip.
+type resonanceMetric struct {
+	label  string
+	max    float64
+	format func(float64) string
+}
+
+func formatPercent(v float64) string { return fmt.Sprintf("%.0f%%", v) }
+func formatRate(v float64) string    { return formatBytes(uint64(v)) + "/s" }
+
+var resonanceMetrics = map[string]resonanceMetric{
+	"cpu":        {label: "CPU", max: 100, format: formatPercent},
+	"memory":     {label: "MEMORY", max: 100, format: formatPercent},
+	"net_rx":     {label: "NET RX", format: formatRate},
+	"net_tx":     {label: "NET TX", format: formatRate},
+	"disk_read":  {label: "DISK READ", format: formatRate},
+	"disk_write": {label: "DISK WRITE", format: formatRate},
+	"load":       {label: "LOAD", format: func(v float64) string { return fmt.Sprintf("%.2f", v) }},
+	"temp":       {label: "TEMP", max: 100, format: func(v float64) string { return fmt.Sprintf("%.0f°C", v) }},
+}
+
+// resonanceBinding is a validated ResonanceConfig
+type resonanceBinding struct {
+	metric string
+	log    bool
+	max    float64
+}
+
+func newResonanceBinding(cfg *ResonanceConfig) (resonanceBinding, error) {
+	b := resonanceBinding{metric: "cpu"}
+	if cfg == nil {
+		return b, nil
+	}
+	if cfg.Metric != "" {
+		if _, ok := resonanceMetrics[cfg.Metric]; !ok {
+			names := make([]string, 0, len(resonanceMetrics))
+			for name := range resonanceMetrics {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return b, fmt.Errorf("resonance: unknown metric %q (want one of %s)", cfg.Metric, strings.Join(names, ", "))
+		}
+		b.metric = cfg.Metric
+	}
+	switch cfg.Scale {
+	case "", "linear":
+	case "log":
+		b.log = true
+	default:
+		return b, fmt.Errorf("resonance: unknown scale %q (want \"linear\" or \"log\")", cfg.Scale)
+	}
+	if cfg.Max < 0 {
+		return b, fmt.Errorf("resonance: max must not be negative, got %v", cfg.Max)
+	}
+	b.max = cfg.Max
+	return b, nil
+}
+
+func (b resonanceBinding) diskIO() bool {
+	return b.metric == "disk_read" || b.metric == "disk_write"
+}
+
+// diskIOMsg is the cumulative bytes moved by every physical disk
+type diskIOMsg struct {
+	at    time.Time
+	read  uint64
+	write uint64
+	err   error
+}
+
+func collectDiskIOCommand() tea.Cmd {
+	return func() tea.Msg {
+		return collectDiskIO()
+	}
+}
+
+func collectDiskIO() diskIOMsg {
+	msg := diskIOMsg{at: time.Now()}
+	counters, err := disk.IOCounters()
+	if err != nil {
+		msg.err = err
+		return msg
+	}
+	for name, c := range counters {
+		if !physicalDisk(name, counters) {
+			continue
+		}
+		msg.read += c.ReadBytes
+		msg.write += c.WriteBytes
+	}
+	return msg
+}
+
+// physicalDisk reports whether a block device should be counted. Partitions,
+// device mapper, RAID and loop devices repeat IO already counted on a disk.
+func physicalDisk(name string, all map[string]disk.IOCountersStat) bool {
+	for _, prefix := range []string{"loop", "ram", "zram", "dm-", "md"} {
+		if strings.HasPrefix(name, prefix) {
+			return false
+		}
+	}
+	for other := range all {
+		if partitionOf(name, other) {
+			return false
+		}
+	}
+	return true
+}
+
+// partitionOf reports whether name is a partition of disk: sda1 of sda, or
+// nvme0n1p2 of nvme0n1 where the disk name ends in a digit. sdaa and
+// nvme0n10 are disks of their own.
+func partitionOf(name, disk string) bool {
+	rest, ok := strings.CutPrefix(name, disk)
+	if !ok || disk == "" {
+		return false
+	}
+	if last := disk[len(disk)-1]; last >= '0' && last <= '9' {
+		if rest, ok = strings.CutPrefix(rest, "p"); !ok {
+			return false
+		}
+	}
+	if rest == "" {
+		return false
+	}
+	for _, r := range rest {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// --- Model glue ---
+
+// rate turns two cumulative counter readings into bytes per second. A
+// counter that went backwards was reset; that sample reads as zero.
+func rate(prev, cur uint64, elapsed time.Duration) float64 {
+	if elapsed <= 0 || cur < prev {
+		return 0
+	}
+	return float64(cur-prev) / elapsed.Seconds()
+}
+
+// setDiskIO turns disk counters into rates for the resonance strip
+func (m *model) setDiskIO(msg diskIOMsg) {
+	if msg.err != nil {
+		return
+	}
+	if !m.lastDiskIO.at.IsZero() {
+		elapsed := msg.at.Sub(m.lastDiskIO.at)
+		m.diskReadRate = rate(m.lastDiskIO.read, msg.read, elapsed)
+		m.diskWriteRate = rate(m.lastDiskIO.write, msg.write, elapsed)
+	}
+	m.lastDiskIO = msg
+}
+
+// resonanceValue reads the bound metric's current value. Rates need two
+// readings, so they are missing at first. Disk, load and temperature come
+// from this machine's collectors, so they are missing for other hosts.
+func (m *model) resonanceValue(s Sample) (float64, bool) {
+	now := m.now()
+	elapsed := now.Sub(m.lastSampleAt)
+	haveRate := !m.lastSampleAt.IsZero()
+	defer func() { m.lastSample, m.lastSampleAt = s, now }()
+
+	switch m.resBinding.metric {
+	case "cpu":
+		return s.CPUPercent, true
+	case "memory":
+		return s.MemUsedPercent, true
+	case "net_rx":
+		return rate(m.lastSample.NetBytesRecv, s.NetBytesRecv, elapsed), haveRate
+	case "net_tx":
+		return rate(m.lastSample.NetBytesSent, s.NetBytesSent, elapsed), haveRate
+	case "disk_read":
+		return m.diskReadRate, m.localHost() && !m.lastDiskIO.at.IsZero()
+	case "disk_write":
+		return m.diskWriteRate, m.localHost() && !m.lastDiskIO.at.IsZero()
+	case "load":
+		return m.memory.load.Load1, m.localHost() && m.memory.cpus > 0
+	case "temp":
+		hot, _, ok := m.hottest()
+		return hot.Celsius, m.localHost() && ok
+	}
+	return 0, false
+}
+
+// pushResonance shifts the bound metric's latest value into the strip
+func (m *model) pushResonance(s Sample) {
+	v, ok := m.resonanceValue(s)
+	if !ok {
+		return
+	}
+	m.resonance = append(m.resonance, v)
+	if len(m.resonance) > resonanceLen {
+		m.resonance = m.resonance[len(m.resonance)-resonanceLen:]
+	}
+}
+
+// resonanceHistory maps strip metrics onto HistorySource metrics. Network
+// totals are cumulative counters and backfill as rates.
+var resonanceHistory = map[string]struct {
+	metric  string
+	counter bool
+}{
+	"cpu":    {metric: promCPU},
+	"memory": {metric: promMemory},
+	"net_rx": {metric: promNetRecv, counter: true},
+	"net_tx": {metric: promNetSent, counter: true},
+}
+
+// resonanceHistoryMsg is a backfill for the strip, oldest first
+type resonanceHistoryMsg struct {
+	metric string
+	values []float64
+	err    error
+}
+
+// resonanceHistoryCommand fetches the strip's recent past when the metric
+// source keeps one, so it starts full instead of empty
+func (m model) resonanceHistoryCommand() tea.Cmd {
+	src, ok := m.source.(HistorySource)
+	h, known := resonanceHistory[m.resBinding.metric]
+	if !ok || !known || m.remote != nil {
+		return nil
+	}
+	metric := m.resBinding.metric
+	return func() tea.Msg {
+		values, err := src.History(h.metric, resonanceLen*resonanceStep, resonanceStep)
+		if err == nil && h.counter {
+			rates := make([]float64, 0, len(values))
+			for i := 1; i < len(values); i++ {
+				rates = append(rates, rate(uint64(max(values[i-1], 0)), uint64(max(values[i], 0)), resonanceStep))
+			}
+			values = rates
+		}
+		return resonanceHistoryMsg{metric: metric, values: values, err: err}
+	}
+}
+
+// backfillResonance puts history in front of the samples collected since
+// the request went out. A backfill for a metric or host the strip no longer
+// shows is dropped.
+func (m *model) backfillResonance(msg resonanceHistoryMsg) {
+	if msg.err != nil {
+		m.addLog(fmt.Sprintf("Resonance history: %v", msg.err))
+		return
+	}
+	if msg.metric != m.resBinding.metric || m.remote != nil {
+		return
+	}
+	m.resonance = append(append([]float64(nil), msg.values...), m.resonance...)
+	if len(m.resonance) > resonanceLen {
+		m.resonance = m.resonance[len(m.resonance)-resonanceLen:]
+	}
+}
+
+// resonanceLevel scales a value onto the strip, 0-1
+func (m model) resonanceLevel(v float64) float64 {
+	top := m.resBinding.max
+	if top == 0 {
+		top = resonanceMetrics[m.resBinding.metric].max
+	}
+	if top == 0 {
+		for _, r := range m.resonance {
+			top = max(top, r)
+		}
+	}
+	if top <= 0 || v <= 0 {
+		return 0
+	}
+	if m.resBinding.log {
+		return min(math.Log1p(v)/math.Log1p(top), 1)
+	}
+	return min(v/top, 1)
+}
+
+// renderResonance draws the strip with the bound metric and its value
+func (m model) renderResonance() string {
+	theme := m.getTheme()
+	bars := []string{" ", "▂", "▃", "▄", "▅", "▆", "▇", "█"}
+
+	var sb strings.Builder
+	sb.WriteString(strings.Repeat(" ", resonanceLen-len(m.resonance)))
+	for _, v := range m.resonance {
+		idx := int(m.resonanceLevel(v) * float64(len(bars)-1))
+		sb.WriteString(bars[min(max(idx, 0), len(bars)-1)])
+	}
+
+	metric := resonanceMetrics[m.resBinding.metric]
+	value := "--"
+	if n := len(m.resonance); n > 0 {
+		value = metric.format(m.resonance[n-1])
+	}
+	label := metric.label
+	if m.resBinding.log {
+		label += " (LOG)"
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Center,
+		lipgloss.NewStyle().Foreground(theme.Accent).Render(sb.String()),
+		lipgloss.NewStyle().Foreground(theme.Dim).Render(label+" ")+
+			lipgloss.NewStyle().Foreground(theme.Primary).Bold(true).Render(value),
+	)
+}
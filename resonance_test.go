@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/shirou/gopsutil/v3/disk"
+)
+
+func TestResonanceBinding(t *testing.T) {
+	b, err := newResonanceBinding(&ResonanceConfig{Metric: "net_rx", Scale: "log"})
+	if err != nil || b.metric != "net_rx" || !b.log {
+		t.Errorf("binding = %+v, %v", b, err)
+	}
+	if b, _ := newResonanceBinding(nil); b.metric != "cpu" || b.log {
+		t.Errorf("default binding = %+v", b)
+	}
+	for _, bad := range []ResonanceConfig{{Metric: "gpu"}, {Scale: "cubic"}, {Max: -1}} {
+		if _, err := newResonanceBinding(&bad); err == nil {
+			t.Errorf("%+v accepted", bad)
+		}
+	}
+}
+
+func TestResonanceFollowsMetric(t *testing.T) {
+	m := newTestModel(t, 120, 40)
+	clock := testTime
+	m.now = func() time.Time { return clock }
+	if err := m.applyConfig(Config{Resonance: &ResonanceConfig{Metric: "net_rx"}}); err != nil {
+		t.Fatal(err)
+	}
+	m.resonance, m.lastSampleAt = nil, time.Time{}
+
+	// A rate needs two samples: 2 KiB over 2s, then 8 KiB
+	recv := uint64(1 << 20)
+	for _, delta := range []uint64{0, 2 << 10, 8 << 10} {
+		recv += delta
+		m = step(m, sampleMsg{sample: Sample{NetBytesRecv: recv}})
+		clock = clock.Add(2 * time.Second)
+	}
+	if len(m.resonance) != 2 || m.resonance[0] != 1024 || m.resonance[1] != 4096 {
+		t.Fatalf("resonance = %v", m.resonance)
+	}
+	got := ansi.Strip(m.renderResonance())
+	if !strings.Contains(got, "▂█") || !strings.Contains(got, "NET RX 4.0 KiB/s") {
+		t.Errorf("strip autoscales to its largest value:\n%s", got)
+	}
+
+	// Log scale lifts the small value
+	m.resBinding.log = true
+	if lin, log := float64(1024)/4096, m.resonanceLevel(1024); log <= lin {
+		t.Errorf("log level %v not above linear %v", log, lin)
+	}
+	if got := ansi.Strip(m.renderResonance()); !strings.Contains(got, "NET RX (LOG)") {
+		t.Errorf("label does not name the scale:\n%s", got)
+	}
+
+	// The strip keeps resonanceLen samples
+	for i := 0; i < resonanceLen+5; i++ {
+		m = step(m, sampleMsg{sample: Sample{NetBytesRecv: recv}})
+	}
+	if len(m.resonance) != resonanceLen {
+		t.Errorf("strip holds %d samples", len(m.resonance))
+	}
+}
+
+func TestResonanceBackfill(t *testing.T) {
+	srv := fakePrometheus(t, nil, map[string]string{
+		"cluster_rx": `{"status":"success","data":{"resultType":"matrix","result":[{"metric":{},"values":[[1,"0"],[3,"2048"],[5,"10240"]]}]}}`,
+	})
+	defer srv.Close()
+
+	m := newTestModel(t, 120, 40)
+	err := m.applyConfig(Config{
+		Source: &SourceConfig{Type: "prometheus", Prometheus: PrometheusConfig{
+			URL:         srv.URL + "/prom",
+			BearerToken: "s3cret",
+			Queries:     map[string]string{"net_recv": "cluster_rx"},
+		}},
+		Resonance: &ResonanceConfig{Metric: "net_rx"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A live sample lands before the history does and stays newest
+	m.resonance = []float64{512}
+	cmd := m.resonanceHistoryCommand()
+	if cmd == nil {
+		t.Fatal("no backfill for a Prometheus source")
+	}
+	m = step(m, cmd())
+	if want := []float64{1024, 4096, 512}; fmt.Sprint(m.resonance) != fmt.Sprint(want) {
+		t.Errorf("resonance = %v, want %v", m.resonance, want)
+	}
+
+	// A backfill for a metric the strip has moved off is dropped
+	m = step(m, resonanceHistoryMsg{metric: "cpu", values: []float64{99}})
+	if len(m.resonance) != 3 {
+		t.Errorf("stale backfill applied: %v", m.resonance)
+	}
+
+	// Sources without history, and metrics Prometheus does not serve, skip it
+	m.resBinding.metric = "temp"
+	if m.resonanceHistoryCommand() != nil {
+		t.Error("backfill for a metric without a query")
+	}
+	m.source = localSource{}
+	m.resBinding.metric = "cpu"
+	if m.resonanceHistoryCommand() != nil {
+		t.Error("backfill from the local source")
+	}
+}
+
+func TestDiskIORates(t *testing.T) {
+	all := map[string]disk.IOCountersStat{
+		"sda": {}, "sda1": {}, "sdaa": {}, "sdaa3": {},
+		"nvme0n1": {}, "nvme0n1p2": {}, "nvme0n10": {}, "mmcblk0": {}, "mmcblk0p1": {},
+		"dm-0": {}, "loop3": {},
+	}
+	var counted []string
+	for name := range all {
+		if physicalDisk(name, all) {
+			counted = append(counted, name)
+		}
+	}
+	sort.Strings(counted)
+	if want := "mmcblk0 nvme0n1 nvme0n10 sda sdaa"; strings.Join(counted, " ") != want {
+		t.Errorf("counted %v, want %s", counted, want)
+	}
+
+	m := newTestModel(t, 120, 40)
+	m.resBinding, m.resonance = resonanceBinding{metric: "disk_write"}, nil
+	m = step(m, diskIOMsg{at: testTime, read: 100, write: 1000})
+	m = step(m, diskIOMsg{at: testTime.Add(2 * time.Second), read: 100, write: 5000})
+	m = step(m, sampleMsg{})
+	if m.diskWriteRate != 2000 || len(m.resonance) != 1 || m.resonance[0] != 2000 {
+		t.Errorf("write rate = %v, strip = %v", m.diskWriteRate, m.resonance)
+	}
+
+	// A fleet host's samples carry no disk I/O, so the strip waits rather
+	// than repeating this machine's rate
+	m.fleet, _ = newFleet([]AgentConfig{{Name: "web-1", Addr: "10.0.0.11", Token: "t"}})
+	m.openHost(1)
+	m.resBinding = resonanceBinding{metric: "disk_write"}
+	m = step(m, diskIOMsg{at: testTime.Add(4 * time.Second), read: 100, write: 9000})
+	m = step(m, sampleMsg{})
+	if len(m.resonance) != 0 {
+		t.Errorf("strip on a fleet host = %v", m.resonance)
+	}
+}
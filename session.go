@@ -0,0 +1,555 @@
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/shirou/gopsutil/v3/load"
+	"github.com/shirou/gopsutil/v3/net"
+)
+
+// A session recording is a gzip-compressed stream of JSON lines: a header,
+// then one event per message Update received. Since all randomness comes
+// from the seeded model RNG and all time from m.now, feeding the same
+// messages to a model built from the same seed reproduces every frame.
+
+const sessionVersion = 1
+
+type sessionHeader struct {
+	Version int       `json:"version"`
+	Seed    int64     `json:"seed"`
+	Start   time.Time `json:"start"`
+}
+
+// sessionEvent is one recorded message, T milliseconds after the start
+type sessionEvent struct {
+	T    int64           `json:"t"`
+	Kind string          `json:"k"`
+	Data json.RawMessage `json:"d,omitempty"`
+}
+
+// Event kinds. Messages of any other type are not recorded.
+const (
+	eventTick    = "tick"
+	eventSpinner = "spin"
+	eventLog     = "log"
+	eventSample  = "sample"
+	eventProcs   = "procs"
+	eventDisks   = "disks"
+	eventIfaces  = "ifaces"
+	eventThermal = "thermal"
+	eventPower   = "power"
+	eventMemory  = "memory"
+	eventConns   = "conns"
+	eventAudio   = "audio"
+	eventDiskIO  = "diskio"
+	eventHistory = "history"
+	eventDocker  = "docker"
+	eventAction  = "action"
+	eventTail    = "tail"
+	eventTailLog = "taillog"
+	eventFleet   = "fleet"
+	eventWidget  = "widget"
+	eventKey     = "key"
+	eventMouse   = "mouse"
+	eventResize  = "resize"
+)
+
+type sampleEvent struct {
+	Sample Sample `json:"sample"`
+	Err    string `json:"err,omitempty"`
+}
+
+type procsEvent struct {
+	Procs []processInfo `json:"procs,omitempty"`
+	Err   string        `json:"err,omitempty"`
+}
+
+type thermalEvent struct {
+	Temps []tempReading `json:"temps,omitempty"`
+	Fans  []fanReading  `json:"fans,omitempty"`
+	Err   string        `json:"err,omitempty"`
+}
+
+type powerEvent struct {
+	Batteries []batteryInfo `json:"batteries,omitempty"`
+	HasAC     bool          `json:"has_ac,omitempty"`
+	ACOnline  bool          `json:"ac_online,omitempty"`
+	Err       string        `json:"err,omitempty"`
+}
+
+type memoryEvent struct {
+	Mem    memoryInfo   `json:"mem"`
+	Load   load.AvgStat `json:"load"`
+	CPUs   int          `json:"cpus"`
+	PSI    []psiStat    `json:"psi,omitempty"`
+	PSIErr string       `json:"psi_err,omitempty"`
+	Err    string       `json:"err,omitempty"`
+}
+
+type connsEvent struct {
+	Conns     []connInfo   `json:"conns,omitempty"`
+	Listeners []listenInfo `json:"listeners,omitempty"`
+	Err       string       `json:"err,omitempty"`
+}
+
+type audioEvent struct {
+	Levels []float64 `json:"levels,omitempty"`
+	Err    string    `json:"err,omitempty"`
+}
+
+type diskIOEvent struct {
+	At    time.Time `json:"at"`
+	Read  uint64    `json:"read"`
+	Write uint64    `json:"write"`
+	Err   string    `json:"err,omitempty"`
+}
+
+type historyEvent struct {
+	Metric string    `json:"metric"`
+	Values []float64 `json:"values,omitempty"`
+	Err    string    `json:"err,omitempty"`
+}
+
+type dockerEvent struct {
+	Containers []containerInfo `json:"containers,omitempty"`
+	Err        string          `json:"err,omitempty"`
+}
+
+type actionEvent struct {
+	Name   string `json:"name"`
+	Action string `json:"action"`
+	Err    string `json:"err,omitempty"`
+}
+
+// widgetEvent holds a poll result by widget index, so replay needs the
+// same widgets configured
+type widgetEvent struct {
+	Idx   int    `json:"idx"`
+	Value any    `json:"value,omitempty"`
+	Err   string `json:"err,omitempty"`
+}
+
+// tailEvent and tailLogEvent record a followed container log by container
+// name; replay stands in a stream with no body
+type tailEvent struct {
+	Name string `json:"name,omitempty"`
+	Err  string `json:"err,omitempty"`
+}
+
+type tailLogEvent struct {
+	Name string `json:"name"`
+	Line string `json:"line,omitempty"`
+	Err  string `json:"err,omitempty"`
+}
+
+type ifacesEvent struct {
+	At    time.Time            `json:"at"`
+	Stats []net.IOCountersStat `json:"stats"`
+}
+
+// encodeMsg converts a message into an event kind and payload
+func encodeMsg(msg tea.Msg) (string, any, bool) {
+	switch msg := msg.(type) {
+	case tickMsg:
+		return eventTick, nil, true
+	case spinner.TickMsg:
+		// The spinner's tag is unexported, so replay asks the spinner for a fresh tick
+		return eventSpinner, nil, true
+	case logMsg:
+		return eventLog, string(msg), true
+	case sampleMsg:
+		ev := sampleEvent{Sample: msg.sample}
+		if msg.err != nil {
+			ev.Err = msg.err.Error()
+		}
+		return eventSample, ev, true
+	case processesMsg:
+		ev := procsEvent{Procs: msg.procs}
+		if msg.err != nil {
+			ev.Err = msg.err.Error()
+		}
+		return eventProcs, ev, true
+	case disksMsg:
+		return eventDisks, []diskInfo(msg), true
+	case ifacesMsg:
+		return eventIfaces, ifacesEvent{At: msg.at, Stats: msg.stats}, true
+	case thermalMsg:
+		ev := thermalEvent{Temps: msg.temps, Fans: msg.fans}
+		if msg.err != nil {
+			ev.Err = msg.err.Error()
+		}
+		return eventThermal, ev, true
+	case powerMsg:
+		ev := powerEvent{Batteries: msg.batteries, HasAC: msg.hasAC, ACOnline: msg.acOnline}
+		if msg.err != nil {
+			ev.Err = msg.err.Error()
+		}
+		return eventPower, ev, true
+	case memoryMsg:
+		ev := memoryEvent{Mem: msg.mem, Load: msg.load, CPUs: msg.cpus, PSI: msg.psi}
+		if msg.psiErr != nil {
+			ev.PSIErr = msg.psiErr.Error()
+		}
+		if msg.err != nil {
+			ev.Err = msg.err.Error()
+		}
+		return eventMemory, ev, true
+	case connectionsMsg:
+		ev := connsEvent{Conns: msg.conns, Listeners: msg.listeners}
+		if msg.err != nil {
+			ev.Err = msg.err.Error()
+		}
+		return eventConns, ev, true
+	case audioMsg:
+		ev := audioEvent{Levels: msg.levels}
+		if msg.err != nil {
+			ev.Err = msg.err.Error()
+		}
+		return eventAudio, ev, true
+	case diskIOMsg:
+		ev := diskIOEvent{At: msg.at, Read: msg.read, Write: msg.write}
+		if msg.err != nil {
+			ev.Err = msg.err.Error()
+		}
+		return eventDiskIO, ev, true
+	case resonanceHistoryMsg:
+		ev := historyEvent{Metric: msg.metric, Values: msg.values}
+		if msg.err != nil {
+			ev.Err = msg.err.Error()
+		}
+		return eventHistory, ev, true
+	case containersMsg:
+		ev := dockerEvent{Containers: msg.containers}
+		if msg.err != nil {
+			ev.Err = msg.err.Error()
+		}
+		return eventDocker, ev, true
+	case containerActionMsg:
+		ev := actionEvent{Name: msg.name, Action: msg.action}
+		if msg.err != nil {
+			ev.Err = msg.err.Error()
+		}
+		return eventAction, ev, true
+	case containerLogStartedMsg:
+		var ev tailEvent
+		if msg.stream != nil {
+			ev.Name = msg.stream.name
+		}
+		if msg.err != nil {
+			ev.Err = msg.err.Error()
+		}
+		return eventTail, ev, true
+	case containerLogMsg:
+		ev := tailLogEvent{Name: msg.stream.name, Line: msg.line}
+		if msg.err != nil {
+			ev.Err = msg.err.Error()
+		}
+		return eventTailLog, ev, true
+	case fleetMsg:
+		return eventFleet, []hostStatus(msg), true
+	case widgetResultMsg:
+		ev := widgetEvent{Idx: msg.idx, Value: msg.value}
+		if msg.err != nil {
+			ev.Err = msg.err.Error()
+		}
+		return eventWidget, ev, true
+	case tea.KeyMsg:
+		return eventKey, tea.Key(msg), true
+	case tea.MouseMsg:
+		return eventMouse, tea.MouseEvent(msg), true
+	case tea.WindowSizeMsg:
+		return eventResize, msg, true
+	}
+	return "", nil, false
+}
+
+// decodeMsg rebuilds the message for an event. The model is needed to mint
+// spinner ticks it will accept.
+func decodeMsg(ev sessionEvent, at time.Time, m model) (tea.Msg, error) {
+	switch ev.Kind {
+	case eventTick:
+		return tickMsg(at), nil
+	case eventSpinner:
+		return m.spinner.Tick(), nil
+	case eventLog:
+		var s string
+		err := json.Unmarshal(ev.Data, &s)
+		return logMsg(s), err
+	case eventSample:
+		var s sampleEvent
+		if err := json.Unmarshal(ev.Data, &s); err != nil {
+			return nil, err
+		}
+		msg := sampleMsg{sample: s.Sample}
+		if s.Err != "" {
+			msg.err = errors.New(s.Err)
+		}
+		return msg, nil
+	case eventProcs:
+		var p procsEvent
+		if err := json.Unmarshal(ev.Data, &p); err != nil {
+			return nil, err
+		}
+		msg := processesMsg{procs: p.Procs}
+		if p.Err != "" {
+			msg.err = errors.New(p.Err)
+		}
+		return msg, nil
+	case eventDisks:
+		var d []diskInfo
+		err := json.Unmarshal(ev.Data, &d)
+		return disksMsg(d), err
+	case eventIfaces:
+		var i ifacesEvent
+		err := json.Unmarshal(ev.Data, &i)
+		return ifacesMsg{at: i.At, stats: i.Stats}, err
+	case eventThermal:
+		var t thermalEvent
+		if err := json.Unmarshal(ev.Data, &t); err != nil {
+			return nil, err
+		}
+		msg := thermalMsg{temps: t.Temps, fans: t.Fans}
+		if t.Err != "" {
+			msg.err = errors.New(t.Err)
+		}
+		return msg, nil
+	case eventPower:
+		var p powerEvent
+		if err := json.Unmarshal(ev.Data, &p); err != nil {
+			return nil, err
+		}
+		msg := powerMsg{batteries: p.Batteries, hasAC: p.HasAC, acOnline: p.ACOnline}
+		if p.Err != "" {
+			msg.err = errors.New(p.Err)
+		}
+		return msg, nil
+	case eventMemory:
+		var e memoryEvent
+		if err := json.Unmarshal(ev.Data, &e); err != nil {
+			return nil, err
+		}
+		msg := memoryMsg{mem: e.Mem, load: e.Load, cpus: e.CPUs, psi: e.PSI}
+		if e.PSIErr != "" {
+			msg.psiErr = errors.New(e.PSIErr)
+		}
+		if e.Err != "" {
+			msg.err = errors.New(e.Err)
+		}
+		return msg, nil
+	case eventConns:
+		var c connsEvent
+		if err := json.Unmarshal(ev.Data, &c); err != nil {
+			return nil, err
+		}
+		msg := connectionsMsg{conns: c.Conns, listeners: c.Listeners}
+		if c.Err != "" {
+			msg.err = errors.New(c.Err)
+		}
+		return msg, nil
+	case eventAudio:
+		var a audioEvent
+		if err := json.Unmarshal(ev.Data, &a); err != nil {
+			return nil, err
+		}
+		msg := audioMsg{levels: a.Levels}
+		if a.Err != "" {
+			msg.err = errors.New(a.Err)
+		}
+		return msg, nil
+	case eventDiskIO:
+		var d diskIOEvent
+		if err := json.Unmarshal(ev.Data, &d); err != nil {
+			return nil, err
+		}
+		msg := diskIOMsg{at: d.At, read: d.Read, write: d.Write}
+		if d.Err != "" {
+			msg.err = errors.New(d.Err)
+		}
+		return msg, nil
+	case eventHistory:
+		var h historyEvent
+		if err := json.Unmarshal(ev.Data, &h); err != nil {
+			return nil, err
+		}
+		msg := resonanceHistoryMsg{metric: h.Metric, values: h.Values}
+		if h.Err != "" {
+			msg.err = errors.New(h.Err)
+		}
+		return msg, nil
+	case eventDocker:
+		var d dockerEvent
+		if err := json.Unmarshal(ev.Data, &d); err != nil {
+			return nil, err
+		}
+		msg := containersMsg{containers: d.Containers}
+		if d.Err != "" {
+			msg.err = errors.New(d.Err)
+		}
+		return msg, nil
+	case eventAction:
+		var a actionEvent
+		if err := json.Unmarshal(ev.Data, &a); err != nil {
+			return nil, err
+		}
+		msg := containerActionMsg{name: a.Name, action: a.Action}
+		if a.Err != "" {
+			msg.err = errors.New(a.Err)
+		}
+		return msg, nil
+	case eventTail:
+		var e tailEvent
+		if err := json.Unmarshal(ev.Data, &e); err != nil {
+			return nil, err
+		}
+		if e.Err != "" {
+			return containerLogStartedMsg{err: errors.New(e.Err)}, nil
+		}
+		return containerLogStartedMsg{stream: &containerLogStream{name: e.Name}}, nil
+	case eventTailLog:
+		var e tailLogEvent
+		if err := json.Unmarshal(ev.Data, &e); err != nil {
+			return nil, err
+		}
+		// Lines belong to the stream being followed unless it has changed
+		stream := m.logTail
+		if stream == nil || stream.name != e.Name {
+			stream = &containerLogStream{name: e.Name}
+		}
+		msg := containerLogMsg{stream: stream, line: e.Line}
+		if e.Err != "" {
+			msg.err = errors.New(e.Err)
+		}
+		return msg, nil
+	case eventFleet:
+		var h []hostStatus
+		err := json.Unmarshal(ev.Data, &h)
+		return fleetMsg(h), err
+	case eventWidget:
+		var w widgetEvent
+		if err := json.Unmarshal(ev.Data, &w); err != nil {
+			return nil, err
+		}
+		msg := widgetResultMsg{idx: w.Idx, value: w.Value}
+		if w.Err != "" {
+			msg.err = errors.New(w.Err)
+		}
+		return msg, nil
+	case eventKey:
+		var k tea.Key
+		err := json.Unmarshal(ev.Data, &k)
+		return tea.KeyMsg(k), err
+	case eventMouse:
+		var e tea.MouseEvent
+		err := json.Unmarshal(ev.Data, &e)
+		return tea.MouseMsg(e), err
+	case eventResize:
+		var w tea.WindowSizeMsg
+		err := json.Unmarshal(ev.Data, &w)
+		return w, err
+	}
+	return nil, fmt.Errorf("unknown event kind %q", ev.Kind)
+}
+
+// --- Recording ---
+
+type sessionWriter struct {
+	zw    *gzip.Writer
+	enc   *json.Encoder
+	start time.Time
+	now   func() time.Time
+	err   error
+}
+
+func newSessionWriter(w io.Writer, seed int64, now func() time.Time) (*sessionWriter, error) {
+	zw := gzip.NewWriter(w)
+	sw := &sessionWriter{zw: zw, enc: json.NewEncoder(zw), start: now(), now: now}
+	if err := sw.enc.Encode(sessionHeader{Version: sessionVersion, Seed: seed, Start: sw.start}); err != nil {
+		return nil, err
+	}
+	return sw, nil
+}
+
+// record appends msg to the session if it is a recordable type. The first
+// write error is kept and reported by Close.
+func (sw *sessionWriter) record(msg tea.Msg) {
+	if sw.err != nil {
+		return
+	}
+	kind, payload, ok := encodeMsg(msg)
+	if !ok {
+		return
+	}
+	ev := sessionEvent{T: sw.now().Sub(sw.start).Milliseconds(), Kind: kind}
+	if payload != nil {
+		if ev.Data, sw.err = json.Marshal(payload); sw.err != nil {
+			return
+		}
+	}
+	sw.err = sw.enc.Encode(ev)
+}
+
+// filter is a tea.WithFilter hook that records every message on its way to Update
+func (sw *sessionWriter) filter(_ tea.Model, msg tea.Msg) tea.Msg {
+	sw.record(msg)
+	return msg
+}
+
+// Close flushes the compressed stream; it does not close the underlying writer
+func (sw *sessionWriter) Close() error {
+	if err := sw.zw.Close(); sw.err == nil {
+		sw.err = err
+	}
+	return sw.err
+}
+
+// --- Reading ---
+
+type sessionReader struct {
+	header sessionHeader
+	dec    *json.Decoder
+}
+
+func newSessionReader(r io.Reader) (*sessionReader, error) {
+	zr, err := gzip.NewReader(bufio.NewReader(r))
+	if err != nil {
+		return nil, fmt.Errorf("not a session recording: %w", err)
+	}
+	sr := &sessionReader{dec: json.NewDecoder(zr)}
+	if err := sr.dec.Decode(&sr.header); err != nil {
+		return nil, fmt.Errorf("reading session header: %w", err)
+	}
+	if sr.header.Version != sessionVersion {
+		return nil, fmt.Errorf("unsupported session version %d", sr.header.Version)
+	}
+	return sr, nil
+}
+
+// next returns the following event, or io.EOF at the end of the recording
+func (sr *sessionReader) next() (sessionEvent, error) {
+	var ev sessionEvent
+	err := sr.dec.Decode(&ev)
+	return ev, err
+}
+
+// openSession opens a recording for replay
+func openSession(path string) (*sessionReader, *os.File, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	sr, err := newSessionReader(f)
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	return sr, f, nil
+}
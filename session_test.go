@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// TestSessionReplay records a scripted session and checks that replaying it
+// ends on the same frame the live model rendered
+func TestSessionReplay(t *testing.T) {
+	const seed = 7
+
+	clock := testTime
+	now := func() time.Time { return clock }
+
+	live := initialModel()
+	live.now = now
+	live.rng = rand.New(rand.NewSource(seed))
+	live.source = fakeSource{Sample{CPUPercent: 12, MemUsedPercent: 48}}
+	widgets := []WidgetConfig{{Name: "API LATENCY", URL: "http://status.internal/api"}, {Name: "QUEUE", URL: "http://status.internal/queue"}}
+	var err error
+	if live.widgets, err = newWidgets(widgets); err != nil {
+		t.Fatal(err)
+	}
+	live.Init()
+
+	tail := &containerLogStream{id: "3f2a", name: "web", body: io.NopCloser(strings.NewReader(""))}
+
+	var buf bytes.Buffer
+	rec, err := newSessionWriter(&buf, seed, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msgs := []tea.Msg{
+		tea.WindowSizeMsg{Width: 120, Height: 40},
+		collectSampleCommand(live.source)(),
+		processesMsg{procs: testProcesses()},
+		testDisks(),
+		testThermal(),
+		testPower(),
+		testMemory(),
+		testConns(),
+		audioMsg{levels: []float64{0.9, 0.5, 0.25}},
+		diskIOMsg{at: testTime, read: 4096, write: 8192},
+		resonanceHistoryMsg{metric: "cpu", values: []float64{20, 35, 50}},
+		containersMsg{containers: []containerInfo{
+			{ID: "3f2a", Name: "web", Image: "nginx:1.27", State: "running", Status: "Up 3 hours", CPU: 4.5, MemUsage: 64 << 20, MemLimit: 1 << 30},
+		}},
+		containerActionMsg{name: "web", action: "restart", err: errors.New("conflict")},
+		containerLogStartedMsg{stream: tail},
+		containerLogMsg{stream: tail, line: "GET /healthz 200"},
+		containerLogMsg{stream: tail, err: io.EOF},
+		fleetMsg{
+			{Name: "db-1", Host: "10.0.0.5:7777", State: agentOnline, HasData: true, Sample: Sample{CPUPercent: 71}, Alerts: []agentAlert{{"DISK", 1}}},
+			{Name: "edge", Host: "10.0.0.9:7777", State: agentOffline, Err: "connection refused"},
+		},
+		widgetResultMsg{idx: 0, value: map[string]any{"p99": 182.5, "status": "UP"}},
+		widgetResultMsg{idx: 1, err: errors.New("HTTP 503 Service Unavailable")},
+		logMsg("Repulsor calibration complete"),
+		tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(live.keys.Theme.Keys()[0])},
+		live.spinner.Tick(),
+	}
+	for i := 0; i < 30; i++ {
+		msgs = append(msgs, tickMsg(clock))
+	}
+	msgs = append(msgs, tea.MouseMsg{X: 5, Y: 5, Action: tea.MouseActionPress, Button: tea.MouseButtonLeft})
+
+	for _, msg := range msgs {
+		clock = clock.Add(200 * time.Millisecond)
+		live = step(live, rec.filter(live, msg))
+	}
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	sr, err := newSessionReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sr.header.Seed != seed {
+		t.Fatalf("header seed = %d, want %d", sr.header.Seed, seed)
+	}
+
+	m := initialModel()
+	m.rng = rand.New(rand.NewSource(sr.header.Seed))
+	if m.widgets, err = newWidgets(widgets); err != nil {
+		t.Fatal(err)
+	}
+	r := newReplayModel(m, sr, 1, true)
+	r.Init()
+	for !r.done {
+		r.play()
+	}
+
+	if r.err != nil {
+		t.Fatal(r.err)
+	}
+	if r.played != len(msgs) {
+		t.Errorf("replayed %d events, want %d", r.played, len(msgs))
+	}
+	// Pages the final frame does not show must match too
+	for name, state := range map[string][2]any{
+		"containers": {r.inner.containers, live.containers},
+		"logs":       {r.inner.logs, live.logs},
+		"hosts":      {r.inner.hosts, live.hosts},
+		"widgets":    {r.inner.renderWidgetsPanel(100, 20), live.renderWidgetsPanel(100, 20)},
+	} {
+		if got, want := fmt.Sprint(state[0]), fmt.Sprint(state[1]); got != want {
+			t.Errorf("replayed %s = %s, want %s", name, got, want)
+		}
+	}
+	if !strings.Contains(strings.Join(r.inner.logs, "\n"), "[web] GET /healthz 200") {
+		t.Error("replay is missing the followed container's log line")
+	}
+	if len(r.inner.containers) != 1 || len(r.inner.hosts) != 2 || r.inner.widgets[0].value == nil || r.inner.widgets[1].err == nil {
+		t.Errorf("replay has %d containers, %d hosts and widgets %+v", len(r.inner.containers), len(r.inner.hosts), r.inner.widgets)
+	}
+
+	if got, want := r.inner.View(), live.View(); got != want {
+		t.Errorf("replayed frame differs from live frame\n--- replay ---\n%s\n--- live ---\n%s", got, want)
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/muesli/termenv"
+)
+
+// snapshotSampleWindow is the gap between the two collection passes, so
+// rate-based metrics (CPU, per-process CPU, interface throughput) have a baseline
+const snapshotSampleWindow = 500 * time.Millisecond
+
+type snapshotOptions struct {
+	width, height int
+	plain         bool
+	output        string
+}
+
+// runSnapshot renders a single frame headlessly: size the model, collect
+// one round of metrics, print View() and optionally export it to a file
+func runSnapshot(m model, opts snapshotOptions, stdout io.Writer) error {
+	if opts.width <= 0 || opts.height <= 0 {
+		return fmt.Errorf("invalid snapshot size %dx%d", opts.width, opts.height)
+	}
+
+	// Render with full color regardless of where stdout points; plain output
+	// is produced by stripping, so exports always see the styled frame
+	lipgloss.SetColorProfile(termenv.TrueColor)
+
+	var tm tea.Model = m
+	tm, _ = tm.Update(tea.WindowSizeMsg{Width: opts.width, Height: opts.height})
+	tm = collectRound(tm)
+	time.Sleep(snapshotSampleWindow)
+	tm = collectRound(tm)
+
+	frame := tm.View()
+
+	if opts.output != "" {
+		if err := exportFrame(opts.output, frame, opts.width, opts.height); err != nil {
+			return err
+		}
+	}
+
+	if opts.plain {
+		frame = ansi.Strip(frame)
+	}
+	_, err := fmt.Fprintln(stdout, frame)
+	return err
+}
+
+// collectRound runs every collector synchronously and feeds the results to the model
+func collectRound(tm tea.Model) tea.Model {
+	for _, cmd := range tm.(model).backgroundCollectCommands() {
+		tm, _ = tm.Update(cmd())
+	}
+	return tm
+}
+
+// exportFrame writes a frame to path in the format implied by its extension
+func exportFrame(path, frame string, width, height int) error {
+	var write func(io.Writer) error
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".html", ".htm":
+		write = func(w io.Writer) error { return writeHTML(w, frame) }
+	case ".svg":
+		write = func(w io.Writer) error { return writeSVG(w, frame, width, height) }
+	case ".ans":
+		write = func(w io.Writer) error { _, err := io.WriteString(w, frame+"\n"); return err }
+	case ".txt":
+		write = func(w io.Writer) error { _, err := io.WriteString(w, ansi.Strip(frame)+"\n"); return err }
+	default:
+		return fmt.Errorf("unsupported snapshot format %q (use .html, .svg, .ans or .txt)", ext)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,537 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	gnet "github.com/shirou/gopsutil/v3/net"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// SSHConfig reads a host's vitals over SSH, for machines where nothing can
+// be installed. The remote side only needs /proc and df.
+type SSHConfig struct {
+	Host       string   `json:"host"` // host or host:port
+	User       string   `json:"user"` // default $USER
+	KeyFiles   []string `json:"key_files"`
+	KnownHosts []string `json:"known_hosts"` // default ~/.ssh/known_hosts
+	Timeout    Duration `json:"timeout"`
+}
+
+// Remote commands. Sample reads three files in one round trip, separated
+// by a marker line.
+const (
+	sshSampleCmd = "cat /proc/stat; echo @@; cat /proc/meminfo; echo @@; cat /proc/net/dev"
+	sshNetDevCmd = "cat /proc/net/dev"
+	sshDFCmd     = "df -kPT 2>/dev/null || df -kP"
+	sshPSCmd     = "ps -eo pid=,pcpu=,pmem=,rss=,comm="
+)
+
+const (
+	defaultSSHTimeout = 10 * time.Second
+	sshMinBackoff     = time.Second
+	sshMaxBackoff     = time.Minute
+)
+
+// sshSource is a MetricSource that also supplies disks, interfaces and
+// processes. It keeps one connection open and runs each read in its own
+// session.
+type sshSource struct {
+	host    string
+	addr    string
+	config  *ssh.ClientConfig
+	now     func() time.Time
+	dial    func(network, addr string, cfg *ssh.ClientConfig) (*ssh.Client, error)
+	timeout time.Duration
+
+	mu          sync.Mutex
+	client      *ssh.Client
+	backoff     time.Duration
+	nextAttempt time.Time
+	lastErr     error
+	prevCPU     cpuTimes
+}
+
+func newSSHSource(cfg SSHConfig) (*sshSource, error) {
+	if cfg.Host == "" {
+		return nil, errors.New("ssh source: host is required")
+	}
+	addr := cfg.Host
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(addr, "22")
+	}
+	user := cfg.User
+	if user == "" {
+		user = os.Getenv("USER")
+	}
+
+	home, _ := os.UserHomeDir()
+	knownHosts := cfg.KnownHosts
+	if len(knownHosts) == 0 {
+		knownHosts = []string{filepath.Join(home, ".ssh", "known_hosts")}
+	}
+	for i, p := range knownHosts {
+		knownHosts[i] = expandHome(p, home)
+	}
+	hostKeys, err := knownhosts.New(knownHosts...)
+	if err != nil {
+		return nil, fmt.Errorf("ssh source: %w", err)
+	}
+
+	auth, err := sshAuth(cfg.KeyFiles, home)
+	if err != nil {
+		return nil, fmt.Errorf("ssh source: %w", err)
+	}
+
+	timeout := time.Duration(cfg.Timeout)
+	if timeout <= 0 {
+		timeout = defaultSSHTimeout
+	}
+
+	return &sshSource{
+		host: cfg.Host,
+		addr: addr,
+		config: &ssh.ClientConfig{
+			User:              user,
+			Auth:              auth,
+			HostKeyCallback:   hostKeys,
+			HostKeyAlgorithms: hostKeyAlgorithms(hostKeys, addr),
+			Timeout:           timeout,
+		},
+		now:     time.Now,
+		dial:    ssh.Dial,
+		timeout: timeout,
+	}, nil
+}
+
+// hostKeyAlgorithms lists the key types known_hosts holds for addr. Left to
+// its defaults the client asks for the server's preferred type, which may
+// not be the one on file, and the check fails as a key mismatch. Nil keeps
+// the defaults when the host is unknown.
+func hostKeyAlgorithms(hostKeys ssh.HostKeyCallback, addr string) []string {
+	// Checking a key no host has makes the callback list the known ones
+	probe, err := ssh.NewPublicKey(ed25519.PublicKey(make([]byte, ed25519.PublicKeySize)))
+	if err != nil {
+		return nil
+	}
+	var keyErr *knownhosts.KeyError
+	if err := hostKeys(addr, &net.TCPAddr{IP: net.IPv4zero}, probe); !errors.As(err, &keyErr) {
+		return nil
+	}
+
+	var algos []string
+	for _, k := range keyErr.Want {
+		types := []string{k.Key.Type()}
+		if types[0] == ssh.KeyAlgoRSA {
+			// An RSA key can sign with any of these; prefer SHA-2
+			types = []string{ssh.KeyAlgoRSASHA512, ssh.KeyAlgoRSASHA256, ssh.KeyAlgoRSA}
+		}
+		for _, t := range types {
+			if !slices.Contains(algos, t) {
+				algos = append(algos, t)
+			}
+		}
+	}
+	return algos
+}
+
+// sshAuth offers the running ssh-agent's keys, then the configured key files
+// (or the usual defaults when none are configured)
+func sshAuth(keyFiles []string, home string) ([]ssh.AuthMethod, error) {
+	var methods []ssh.AuthMethod
+	if sock := os.Getenv("SSH_AUTH_SOCK"); sock != "" {
+		if conn, err := net.Dial("unix", sock); err == nil {
+			methods = append(methods, ssh.PublicKeysCallback(agent.NewClient(conn).Signers))
+		}
+	}
+
+	explicit := len(keyFiles) > 0
+	if !explicit {
+		for _, name := range []string{"id_ed25519", "id_ecdsa", "id_rsa"} {
+			keyFiles = append(keyFiles, filepath.Join(home, ".ssh", name))
+		}
+	}
+	var signers []ssh.Signer
+	for _, p := range keyFiles {
+		data, err := os.ReadFile(expandHome(p, home))
+		if err != nil {
+			if explicit {
+				return nil, err
+			}
+			continue
+		}
+		signer, err := ssh.ParsePrivateKey(data)
+		if err != nil {
+			if explicit {
+				return nil, fmt.Errorf("%s: %w (use ssh-agent for passphrase-protected keys)", p, err)
+			}
+			continue
+		}
+		signers = append(signers, signer)
+	}
+	if len(signers) > 0 {
+		methods = append(methods, ssh.PublicKeys(signers...))
+	}
+	if len(methods) == 0 {
+		return nil, errors.New("no ssh-agent and no usable key files")
+	}
+	return methods, nil
+}
+
+func expandHome(p, home string) string {
+	if rest, ok := strings.CutPrefix(p, "~/"); ok {
+		return filepath.Join(home, rest)
+	}
+	return p
+}
+
+// connect returns the open client, dialing if needed. After a failure it
+// waits out an exponential backoff instead of dialing on every tick.
+func (s *sshSource) connect() (*ssh.Client, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.client != nil {
+		return s.client, nil
+	}
+	if now := s.now(); now.Before(s.nextAttempt) {
+		return nil, fmt.Errorf("%w (retrying in %s)", s.lastErr, s.nextAttempt.Sub(now).Round(time.Second))
+	}
+
+	client, err := s.dial("tcp", s.addr, s.config)
+	if err != nil {
+		s.backoff = min(max(s.backoff*2, sshMinBackoff), sshMaxBackoff)
+		s.nextAttempt = s.now().Add(s.backoff)
+		s.lastErr = fmt.Errorf("ssh %s: %w", s.addr, err)
+		return nil, s.lastErr
+	}
+	s.client = client
+	s.backoff = 0
+	return client, nil
+}
+
+// drop forgets a broken connection so the next read redials
+func (s *sshSource) drop(client *ssh.Client) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.client == client {
+		s.client.Close()
+		s.client = nil
+	}
+}
+
+// run executes a command on the remote host and returns its stdout
+func (s *sshSource) run(cmd string) ([]byte, error) {
+	client, err := s.connect()
+	if err != nil {
+		return nil, err
+	}
+	session, err := client.NewSession()
+	if err != nil {
+		// A session that can't open means the connection is gone
+		s.drop(client)
+		return nil, fmt.Errorf("ssh %s: %w", s.addr, err)
+	}
+	defer session.Close()
+
+	var stdout, stderr bytes.Buffer
+	session.Stdout = &stdout
+	session.Stderr = &stderr
+
+	done := make(chan error, 1)
+	go func() { done <- session.Run(cmd) }()
+	select {
+	case err = <-done:
+	case <-time.After(s.timeout):
+		s.drop(client)
+		return nil, fmt.Errorf("ssh %s: %q timed out", s.addr, cmd)
+	}
+
+	if err != nil {
+		var exit *ssh.ExitError
+		if !errors.As(err, &exit) {
+			s.drop(client)
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("ssh %s: %s", s.addr, msg)
+		}
+		return nil, fmt.Errorf("ssh %s: %w", s.addr, err)
+	}
+	return stdout.Bytes(), nil
+}
+
+func (s *sshSource) Sample() (Sample, error) {
+	var sample Sample
+	out, err := s.run(sshSampleCmd)
+	if err != nil {
+		return sample, err
+	}
+	parts := bytes.Split(out, []byte("\n@@\n"))
+	if len(parts) != 3 {
+		return sample, fmt.Errorf("ssh %s: unexpected output from %q", s.addr, sshSampleCmd)
+	}
+
+	cpu, err := parseProcStat(parts[0])
+	if err != nil {
+		return sample, err
+	}
+	if sample.MemUsedPercent, err = parseMeminfo(parts[1]); err != nil {
+		return sample, err
+	}
+	ifaces, err := parseNetDev(parts[2])
+	if err != nil {
+		return sample, err
+	}
+
+	// The first reading averages since boot; later ones cover the interval
+	s.mu.Lock()
+	sample.CPUPercent = cpu.percentSince(s.prevCPU)
+	s.prevCPU = cpu
+	s.mu.Unlock()
+
+	for _, i := range ifaces {
+		sample.NetBytesSent += i.BytesSent
+		sample.NetBytesRecv += i.BytesRecv
+	}
+	return sample, nil
+}
+
+// Disks reads the remote filesystems from df
+func (s *sshSource) Disks() ([]diskInfo, error) {
+	out, err := s.run(sshDFCmd)
+	if err != nil {
+		return nil, err
+	}
+	return parseDF(out)
+}
+
+// Host names the machine in the title bar
+func (s *sshSource) Host() string {
+	return s.host
+}
+
+// Processes reads the remote process table from ps
+func (s *sshSource) Processes() ([]processInfo, error) {
+	out, err := s.run(sshPSCmd)
+	if err != nil {
+		return nil, err
+	}
+	return parsePS(out)
+}
+
+// Ifaces reads the remote per-interface counters
+func (s *sshSource) Ifaces() ([]gnet.IOCountersStat, error) {
+	out, err := s.run(sshNetDevCmd)
+	if err != nil {
+		return nil, err
+	}
+	return parseNetDev(out)
+}
+
+// --- /proc and df parsers ---
+
+// cpuTimes is the aggregate "cpu" line of /proc/stat, in jiffies
+type cpuTimes struct {
+	total, idle uint64
+}
+
+func (c cpuTimes) percentSince(prev cpuTimes) float64 {
+	if c.total <= prev.total || c.idle < prev.idle {
+		prev = cpuTimes{} // first reading or counters reset
+	}
+	total := c.total - prev.total
+	if total == 0 {
+		return 0
+	}
+	busy := total - min(c.idle-prev.idle, total)
+	return float64(busy) / float64(total) * 100
+}
+
+func parseProcStat(data []byte) (cpuTimes, error) {
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) < 5 || fields[0] != "cpu" {
+			continue
+		}
+		// user nice system idle iowait irq softirq steal; guest time is
+		// already counted in user
+		var t cpuTimes
+		for i, f := range fields[1:min(len(fields), 9)] {
+			v, err := strconv.ParseUint(f, 10, 64)
+			if err != nil {
+				return t, fmt.Errorf("/proc/stat: %w", err)
+			}
+			t.total += v
+			if i == 3 || i == 4 { // idle, iowait
+				t.idle += v
+			}
+		}
+		return t, nil
+	}
+	return cpuTimes{}, errors.New("/proc/stat: no aggregate cpu line")
+}
+
+// parseMeminfo returns the used memory percentage the way gopsutil does:
+// everything but MemAvailable counts as used
+func parseMeminfo(data []byte) (float64, error) {
+	kb := make(map[string]uint64)
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		name, rest, ok := strings.Cut(sc.Text(), ":")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(rest)
+		if len(fields) == 0 {
+			continue
+		}
+		if v, err := strconv.ParseUint(fields[0], 10, 64); err == nil {
+			kb[name] = v
+		}
+	}
+
+	total := kb["MemTotal"]
+	if total == 0 {
+		return 0, errors.New("/proc/meminfo: no MemTotal")
+	}
+	avail, ok := kb["MemAvailable"]
+	if !ok {
+		// Kernels before 3.14 lack MemAvailable
+		avail = kb["MemFree"] + kb["Buffers"] + kb["Cached"]
+	}
+	avail = min(avail, total)
+	return float64(total-avail) / float64(total) * 100, nil
+}
+
+// parseNetDev reads /proc/net/dev. Each interface line is
+// "name: rx bytes packets errs drop fifo frame compressed multicast"
+// followed by the same eight tx columns.
+func parseNetDev(data []byte) ([]gnet.IOCountersStat, error) {
+	var stats []gnet.IOCountersStat
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		name, rest, ok := strings.Cut(sc.Text(), ":")
+		if !ok {
+			continue // header lines
+		}
+		fields := strings.Fields(rest)
+		if len(fields) < 16 {
+			return nil, fmt.Errorf("/proc/net/dev: short line for %s", strings.TrimSpace(name))
+		}
+		var v [16]uint64
+		for i := range v {
+			n, err := strconv.ParseUint(fields[i], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("/proc/net/dev: %w", err)
+			}
+			v[i] = n
+		}
+		stats = append(stats, gnet.IOCountersStat{
+			Name:        strings.TrimSpace(name),
+			BytesRecv:   v[0],
+			PacketsRecv: v[1],
+			Errin:       v[2],
+			Dropin:      v[3],
+			Fifoin:      v[4],
+			BytesSent:   v[8],
+			PacketsSent: v[9],
+			Errout:      v[10],
+			Dropout:     v[11],
+			Fifoout:     v[12],
+		})
+	}
+	if len(stats) == 0 {
+		return nil, errors.New("/proc/net/dev: no interfaces")
+	}
+	return stats, nil
+}
+
+// parsePS reads "pid pcpu pmem rss comm" lines with no header. ps reports
+// CPU averaged over each process's lifetime, not the last interval, and
+// RSS in KiB.
+func parsePS(data []byte) ([]processInfo, error) {
+	var procs []processInfo
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) < 5 {
+			continue
+		}
+		pid, err1 := strconv.ParseInt(fields[0], 10, 32)
+		cpu, err2 := strconv.ParseFloat(fields[1], 64)
+		mem, err3 := strconv.ParseFloat(fields[2], 32)
+		rss, err4 := strconv.ParseUint(fields[3], 10, 64)
+		if err := errors.Join(err1, err2, err3, err4); err != nil {
+			return nil, fmt.Errorf("ps: %w", err)
+		}
+		procs = append(procs, processInfo{
+			PID:  int32(pid),
+			Name: strings.Join(fields[4:], " "), // kernel threads may contain spaces
+			CPU:  cpu,
+			Mem:  float32(mem),
+			RSS:  rss << 10,
+		})
+	}
+	return procs, nil
+}
+
+// parseDF reads POSIX df output in 1K blocks, with or without the -T type
+// column: "Filesystem [Type] 1024-blocks Used Available Capacity Mounted on"
+func parseDF(data []byte) ([]diskInfo, error) {
+	var disks []diskInfo
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	typed := false
+	for first := true; sc.Scan(); first = false {
+		fields := strings.Fields(sc.Text())
+		if first {
+			if len(fields) > 1 && fields[1] == "Type" {
+				typed = true
+			}
+			continue
+		}
+
+		fsType := ""
+		if typed {
+			if len(fields) < 7 {
+				continue
+			}
+			fsType = fields[1]
+			fields = append(fields[:1], fields[2:]...)
+		}
+		if len(fields) < 6 {
+			continue
+		}
+		total, err1 := strconv.ParseUint(fields[1], 10, 64)
+		used, err2 := strconv.ParseUint(fields[2], 10, 64)
+		free, err3 := strconv.ParseUint(fields[3], 10, 64)
+		if err := errors.Join(err1, err2, err3); err != nil {
+			return nil, fmt.Errorf("df: %w", err)
+		}
+		if total == 0 || used+free == 0 {
+			continue // pseudo filesystems
+		}
+		disks = append(disks, diskInfo{
+			Mount:       strings.Join(fields[5:], " "), // mount points may contain spaces
+			FSType:      fsType,
+			Total:       total << 10,
+			Used:        used << 10,
+			Free:        free << 10,
+			UsedPercent: float64(used) / float64(used+free) * 100,
+		})
+	}
+	return disks, nil
+}
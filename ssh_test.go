@@ -0,0 +1,440 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+const testMeminfo = `MemTotal:       16000000 kB
+MemFree:         2000000 kB
+MemAvailable:    4000000 kB
+Buffers:          500000 kB
+Cached:          3000000 kB
+`
+
+const testNetDev = `Inter-|   Receive                                                |  Transmit
+ face |bytes    packets errs drop fifo frame compressed multicast|bytes    packets errs drop fifo colls carrier compressed
+    lo:    1000      10    0    0    0     0          0         0     1000      10    0    0    0     0       0          0
+  eth0: 5000000    4000    2    1    0     0          0         0  3000000    2500    3    0    0     0       0          0
+`
+
+const testDF = `Filesystem     Type     1024-blocks      Used Available Capacity Mounted on
+/dev/sda1      ext4       102400000  61440000  40960000      60% /
+tmpfs          tmpfs              0         0         0       0% /dev/shm
+/dev/sdb1      xfs          2048000    512000   1536000      25% /mnt/my data
+`
+
+const testPS = `    1  0.0  0.1  11840 systemd
+  812 12.5  3.2 524288 postgres
+   42  0.0  0.0      0 kworker/0:1 events
+`
+
+func TestProcParsers(t *testing.T) {
+	a, err := parseProcStat([]byte("cpu  100 0 50 800 50 0 0 0 0 0\ncpu0 1 2 3 4\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := parseProcStat([]byte("cpu  160 0 90 880 70 0 0 0 0 0\n"))
+	if got := a.percentSince(cpuTimes{}); got != 15 {
+		t.Errorf("since boot = %v, want 15", got)
+	}
+	// 200 jiffies elapsed, 100 of them idle or iowait
+	if got := b.percentSince(a); got != 50 {
+		t.Errorf("interval = %v, want 50", got)
+	}
+
+	if got, err := parseMeminfo([]byte(testMeminfo)); err != nil || got != 75 {
+		t.Errorf("parseMeminfo = %v, %v; want 75", got, err)
+	}
+	noAvail := strings.Replace(testMeminfo, "MemAvailable:    4000000 kB\n", "", 1)
+	if got, _ := parseMeminfo([]byte(noAvail)); got != 65.625 {
+		t.Errorf("parseMeminfo without MemAvailable = %v, want 65.625", got)
+	}
+
+	ifaces, err := parseNetDev([]byte(testNetDev))
+	if err != nil || len(ifaces) != 2 {
+		t.Fatalf("parseNetDev = %v, %v", ifaces, err)
+	}
+	if eth := ifaces[1]; eth.Name != "eth0" || eth.BytesRecv != 5000000 || eth.BytesSent != 3000000 || eth.Errin != 2 || eth.Errout != 3 {
+		t.Errorf("eth0 = %+v", eth)
+	}
+
+	disks, err := parseDF([]byte(testDF))
+	if err != nil || len(disks) != 2 {
+		t.Fatalf("parseDF = %v, %v", disks, err)
+	}
+	if d := disks[0]; d.Mount != "/" || d.FSType != "ext4" || d.Total != 102400000<<10 || d.UsedPercent != 60 {
+		t.Errorf("root = %+v", d)
+	}
+	if disks[1].Mount != "/mnt/my data" {
+		t.Errorf("mount with a space = %q", disks[1].Mount)
+	}
+
+	procs, err := parsePS([]byte(testPS))
+	if err != nil || len(procs) != 3 {
+		t.Fatalf("parsePS = %v, %v", procs, err)
+	}
+	if p := procs[1]; p.PID != 812 || p.Name != "postgres" || p.CPU != 12.5 || p.Mem != 3.2 || p.RSS != 524288<<10 {
+		t.Errorf("postgres = %+v", p)
+	}
+	if procs[2].Name != "kworker/0:1 events" {
+		t.Errorf("name with a space = %q", procs[2].Name)
+	}
+
+	// Busybox df has no type column
+	plain, _ := parseDF([]byte("Filesystem 1024-blocks Used Available Capacity Mounted on\n/dev/root 1000 250 750 25% /\n"))
+	if len(plain) != 1 || plain[0].FSType != "" || plain[0].UsedPercent != 25 {
+		t.Errorf("untyped df = %+v", plain)
+	}
+}
+
+// fakeSSHServer answers exec requests with canned output. It stands in for
+// a host we can only reach over SSH.
+type fakeSSHServer struct {
+	ln      net.Listener
+	config  *ssh.ServerConfig
+	hostKey ssh.PublicKey
+
+	mu      sync.Mutex
+	outputs map[string]string
+	conns   []net.Conn
+}
+
+// newFakeSSHServer's own host key is ed25519; extra host keys are offered
+// alongside it
+func newFakeSSHServer(t *testing.T, clientKey ssh.PublicKey, extraHostKeys ...ssh.Signer) *fakeSSHServer {
+	t.Helper()
+
+	_, priv, _ := ed25519.GenerateKey(rand.Reader)
+	signer, err := ssh.NewSignerFromKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &fakeSSHServer{hostKey: signer.PublicKey(), outputs: make(map[string]string)}
+	s.config = &ssh.ServerConfig{
+		PublicKeyCallback: func(_ ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if string(key.Marshal()) == string(clientKey.Marshal()) {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("unknown key")
+		},
+	}
+	s.config.AddHostKey(signer)
+	for _, k := range extraHostKeys {
+		s.config.AddHostKey(k)
+	}
+
+	if s.ln, err = net.Listen("tcp", "127.0.0.1:0"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.ln.Close() })
+	go s.serve()
+	return s
+}
+
+func (s *fakeSSHServer) set(cmd, out string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.outputs[cmd] = out
+}
+
+// kill drops every open connection, as a network blip would
+func (s *fakeSSHServer) kill() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, c := range s.conns {
+		c.Close()
+	}
+	s.conns = nil
+}
+
+func (s *fakeSSHServer) serve() {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.conns = append(s.conns, conn)
+		s.mu.Unlock()
+
+		go func() {
+			_, chans, reqs, err := ssh.NewServerConn(conn, s.config)
+			if err != nil {
+				return
+			}
+			go ssh.DiscardRequests(reqs)
+			for nc := range chans {
+				if nc.ChannelType() != "session" {
+					nc.Reject(ssh.UnknownChannelType, "sessions only")
+					continue
+				}
+				ch, reqs, err := nc.Accept()
+				if err != nil {
+					continue
+				}
+				go s.session(ch, reqs)
+			}
+		}()
+	}
+}
+
+func (s *fakeSSHServer) session(ch ssh.Channel, reqs <-chan *ssh.Request) {
+	defer ch.Close()
+	for req := range reqs {
+		if req.Type != "exec" {
+			req.Reply(false, nil)
+			continue
+		}
+		var payload struct{ Command string }
+		ssh.Unmarshal(req.Payload, &payload)
+		req.Reply(true, nil)
+
+		s.mu.Lock()
+		out, ok := s.outputs[payload.Command]
+		s.mu.Unlock()
+
+		status := uint32(0)
+		if ok {
+			ch.Write([]byte(out))
+		} else {
+			fmt.Fprintf(ch.Stderr(), "sh: %s: not found\n", payload.Command)
+			status = 127
+		}
+		ch.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{status}))
+		return
+	}
+}
+
+// sshFixture writes a client key and a known_hosts file trusting hostKey
+func sshFixture(t *testing.T, addr string, hostKey ssh.PublicKey) (keyFile, knownHosts string, clientKey ssh.PublicKey) {
+	t.Helper()
+	dir := t.TempDir()
+
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+	block, err := ssh.MarshalPrivateKey(priv, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyFile = filepath.Join(dir, "id_ed25519")
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(block), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	clientKey, _ = ssh.NewPublicKey(pub)
+
+	knownHosts = filepath.Join(dir, "known_hosts")
+	if hostKey != nil {
+		line := knownhosts.Line([]string{knownhosts.Normalize(addr)}, hostKey) + "\n"
+		os.WriteFile(knownHosts, []byte(line), 0o600)
+	} else {
+		os.WriteFile(knownHosts, nil, 0o600)
+	}
+	return keyFile, knownHosts, clientKey
+}
+
+func TestSSHSource(t *testing.T) {
+	t.Setenv("SSH_AUTH_SOCK", "")
+
+	// The server needs the client key and the client needs the server's
+	// address, so generate the client side against a placeholder first
+	keyFile, _, clientKey := sshFixture(t, "placeholder:22", nil)
+	srv := newFakeSSHServer(t, clientKey)
+	addr := srv.ln.Addr().String()
+	_, knownHosts, _ := sshFixture(t, addr, srv.hostKey)
+
+	stat := "cpu  100 0 50 800 50 0 0 0 0 0\n"
+	srv.set(sshSampleCmd, stat+"@@\n"+testMeminfo+"@@\n"+testNetDev)
+	srv.set(sshDFCmd, testDF)
+	srv.set(sshNetDevCmd, testNetDev)
+	srv.set(sshPSCmd, testPS)
+
+	src, err := newSSHSource(SSHConfig{Host: addr, User: "ops", KeyFiles: []string{keyFile}, KnownHosts: []string{knownHosts}, Timeout: Duration(5 * time.Second)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := src.Sample()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Sample{CPUPercent: 15, MemUsedPercent: 75, NetBytesSent: 3001000, NetBytesRecv: 5001000}
+	if got != want {
+		t.Errorf("Sample() = %+v, want %+v", got, want)
+	}
+
+	srv.set(sshSampleCmd, "cpu  160 0 90 880 70 0 0 0 0 0\n@@\n"+testMeminfo+"@@\n"+testNetDev)
+	if got, _ := src.Sample(); got.CPUPercent != 50 {
+		t.Errorf("second CPUPercent = %v, want 50", got.CPUPercent)
+	}
+
+	// The remote source replaces the local storage, interface and process
+	// collectors
+	m := newTestModel(t, 120, 40)
+	m.source = src
+	m = step(m, m.disksCommand()())
+	m = step(m, m.ifacesCommand()())
+	m = step(m, m.processesCommand()())
+	if len(m.disks) != 2 || m.disks[0].Mount != "/" || len(m.lastIfaces.stats) != 2 {
+		t.Errorf("model got %d disks, %d ifaces", len(m.disks), len(m.lastIfaces.stats))
+	}
+	if len(m.processes) != 3 || m.processes[0].Name != "postgres" {
+		t.Errorf("model got processes %+v, want postgres first", m.processes)
+	}
+	if m.hostName() != addr {
+		t.Errorf("hostName() = %q, want %q", m.hostName(), addr)
+	}
+
+	// and turns off the collectors that can only read this machine
+	m.memReader = &memoryReader{psiRoot: defaultPSIRoot}
+	m.connReader = &connectionReader{}
+	if cmds := m.backgroundCollectCommands(); len(cmds) != 4 {
+		t.Errorf("got %d collectors, want sample, processes, disks and ifaces", len(cmds))
+	}
+
+	// A command failing on the remote side keeps the connection
+	srv.mu.Lock()
+	delete(srv.outputs, sshDFCmd)
+	srv.mu.Unlock()
+	if _, err := src.Disks(); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("missing df: err = %v", err)
+	}
+
+	// A failed ps keeps the last table and says why in the panel title
+	srv.mu.Lock()
+	delete(srv.outputs, sshPSCmd)
+	srv.mu.Unlock()
+	m = step(m, m.processesCommand()())
+	if len(m.processes) != 3 || !strings.Contains(ansi.Strip(m.renderPanel(panelProcesses, 100, 20)), "not found") {
+		t.Errorf("failed ps left %d processes, err %v", len(m.processes), m.procErr)
+	}
+	client := src.client
+
+	// A dropped connection is redialled on the next read
+	srv.kill()
+	src.Sample() // notices the dead connection
+	if _, err := src.Ifaces(); err != nil {
+		t.Errorf("after reconnect: %v", err)
+	}
+	if src.client == nil || src.client == client {
+		t.Error("source did not reconnect")
+	}
+}
+
+func TestSSHSourceHostKeyAndBackoff(t *testing.T) {
+	t.Setenv("SSH_AUTH_SOCK", "")
+
+	keyFile, _, clientKey := sshFixture(t, "placeholder:22", nil)
+	srv := newFakeSSHServer(t, clientKey)
+	addr := srv.ln.Addr().String()
+
+	// known_hosts vouches for a different key
+	_, otherKey, _ := ed25519.GenerateKey(rand.Reader)
+	impostor, _ := ssh.NewSignerFromKey(otherKey)
+	_, knownHosts, _ := sshFixture(t, addr, impostor.PublicKey())
+
+	src, err := newSSHSource(SSHConfig{Host: addr, KeyFiles: []string{keyFile}, KnownHosts: []string{knownHosts}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock := testTime
+	src.now = func() time.Time { return clock }
+	dials := 0
+	src.dial = func(network, addr string, cfg *ssh.ClientConfig) (*ssh.Client, error) {
+		dials++
+		return ssh.Dial(network, addr, cfg)
+	}
+
+	if _, err := src.Sample(); err == nil || !strings.Contains(err.Error(), "key mismatch") {
+		t.Fatalf("mismatched host key: err = %v", err)
+	}
+
+	// Reads inside the backoff window fail fast without dialling
+	for _, wait := range []time.Duration{0, 500 * time.Millisecond} {
+		clock = testTime.Add(wait)
+		if _, err := src.Sample(); err == nil || !strings.Contains(err.Error(), "retrying in") {
+			t.Errorf("+%s: err = %v", wait, err)
+		}
+	}
+	if dials != 1 {
+		t.Errorf("dialled %d times inside the backoff window, want 1", dials)
+	}
+
+	// Each failure doubles the wait
+	for i, want := range []time.Duration{2 * time.Second, 4 * time.Second, 8 * time.Second} {
+		clock = src.nextAttempt
+		src.Sample()
+		if src.backoff != want {
+			t.Errorf("failure %d: backoff = %s, want %s", i+2, src.backoff, want)
+		}
+	}
+}
+
+func TestSSHSourceHostKeyAlgorithms(t *testing.T) {
+	t.Setenv("SSH_AUTH_SOCK", "")
+
+	// The server also offers an ECDSA key, which the client prefers by
+	// default, but known_hosts only has its ed25519 key
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	ecSigner, _ := ssh.NewSignerFromKey(ecKey)
+	keyFile, _, clientKey := sshFixture(t, "placeholder:22", nil)
+	srv := newFakeSSHServer(t, clientKey, ecSigner)
+	addr := srv.ln.Addr().String()
+	_, knownHosts, _ := sshFixture(t, addr, srv.hostKey)
+	srv.set(sshNetDevCmd, testNetDev)
+
+	src, err := newSSHSource(SSHConfig{Host: addr, KeyFiles: []string{keyFile}, KnownHosts: []string{knownHosts}, Timeout: Duration(5 * time.Second)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := src.config.HostKeyAlgorithms; len(got) != 1 || got[0] != ssh.KeyAlgoED25519 {
+		t.Errorf("HostKeyAlgorithms = %v, want [%s]", got, ssh.KeyAlgoED25519)
+	}
+	if _, err := src.Ifaces(); err != nil {
+		t.Errorf("connecting with the stored key type: %v", err)
+	}
+
+	// An unknown host keeps the defaults, and is refused by the callback
+	_, empty, _ := sshFixture(t, addr, nil)
+	unknown, err := newSSHSource(SSHConfig{Host: addr, KeyFiles: []string{keyFile}, KnownHosts: []string{empty}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if unknown.config.HostKeyAlgorithms != nil {
+		t.Errorf("unknown host: HostKeyAlgorithms = %v, want defaults", unknown.config.HostKeyAlgorithms)
+	}
+}
+
+func TestSSHSourceConfig(t *testing.T) {
+	t.Setenv("SSH_AUTH_SOCK", "")
+	dir := t.TempDir()
+	known := filepath.Join(dir, "known_hosts")
+	os.WriteFile(known, nil, 0o600)
+
+	for _, tc := range []struct {
+		cfg SSHConfig
+		err string
+	}{
+		{SSHConfig{}, "host is required"},
+		{SSHConfig{Host: "box", KnownHosts: []string{filepath.Join(dir, "missing")}}, "no such file"},
+		{SSHConfig{Host: "box", KnownHosts: []string{known}, KeyFiles: []string{filepath.Join(dir, "nokey")}}, "no such file"},
+	} {
+		if _, err := newSSHSource(tc.cfg); err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("%+v: err = %v, want %q", tc.cfg, err, tc.err)
+		}
+	}
+}
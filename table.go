@@ -0,0 +1,151 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+)
+
+// tableColumn describes one column of a dataTable. A zero width column
+// absorbs whatever space the fixed columns leave over.
+type tableColumn struct {
+	title string
+	width int
+	right bool
+}
+
+// dataTable is a scrollable table with a row cursor. Unlike the bubbles
+// table it exposes its scroll offset, so mouse clicks map onto rows.
+type dataTable struct {
+	columns []tableColumn
+	rows    [][]string
+	cursor  int
+	offset  int
+	height  int // visible data rows, excluding the header
+}
+
+func newDataTable(columns ...tableColumn) dataTable {
+	return dataTable{columns: columns}
+}
+
+// setRows replaces the table contents, keeping the cursor in range
+func (t *dataTable) setRows(rows [][]string) {
+	t.rows = rows
+	t.clamp()
+}
+
+func (t *dataTable) setHeight(h int) {
+	t.height = max(h, 1)
+	t.clamp()
+}
+
+// moveCursor moves the selection and scrolls it into view
+func (t *dataTable) moveCursor(delta int) {
+	t.cursor += delta
+	t.clamp()
+}
+
+// setCursor selects row i and scrolls it into view
+func (t *dataTable) setCursor(i int) {
+	t.cursor = i
+	t.clamp()
+}
+
+// rowAt maps a line offset from the top of the table (header included) to a row index
+func (t dataTable) rowAt(line int) (int, bool) {
+	idx := t.offset + line - 1
+	if line < 1 || line > t.height || idx >= len(t.rows) {
+		return 0, false
+	}
+	return idx, true
+}
+
+func (t *dataTable) clamp() {
+	if t.cursor >= len(t.rows) {
+		t.cursor = len(t.rows) - 1
+	}
+	if t.cursor < 0 {
+		t.cursor = 0
+	}
+	if t.cursor < t.offset {
+		t.offset = t.cursor
+	}
+	if t.height > 0 && t.cursor >= t.offset+t.height {
+		t.offset = t.cursor - t.height + 1
+	}
+	if maxOffset := len(t.rows) - t.height; t.offset > maxOffset {
+		t.offset = max(maxOffset, 0)
+	}
+}
+
+// columnWidths resolves flexible columns against the available width
+func (t dataTable) columnWidths(width int) []int {
+	widths := make([]int, len(t.columns))
+	fixed, flex := 0, 0
+	for i, c := range t.columns {
+		widths[i] = c.width
+		fixed += c.width + 1
+		if c.width == 0 {
+			flex++
+		}
+	}
+	if flex > 0 {
+		share := max((width-fixed)/flex, 4)
+		for i, c := range t.columns {
+			if c.width == 0 {
+				widths[i] = share
+			}
+		}
+	}
+	return widths
+}
+
+func (t dataTable) formatRow(cells []string, widths []int) string {
+	parts := make([]string, len(widths))
+	for i, w := range widths {
+		cell := ""
+		if i < len(cells) {
+			cell = runewidth.Truncate(cells[i], w, "…")
+		}
+		if t.columns[i].right {
+			parts[i] = runewidth.FillLeft(cell, w)
+		} else {
+			parts[i] = runewidth.FillRight(cell, w)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// view renders the header and the visible rows. The cursor row is only
+// highlighted while the table's panel has focus.
+func (t dataTable) view(width int, theme Theme, focused bool) string {
+	widths := t.columnWidths(width)
+
+	headerCells := make([]string, len(t.columns))
+	for i, c := range t.columns {
+		headerCells[i] = c.title
+	}
+
+	headerRow := lipgloss.NewStyle().Foreground(theme.Accent).Bold(true)
+	rowStyle := lipgloss.NewStyle().Foreground(theme.Primary)
+	selectedStyle := lipgloss.NewStyle().Foreground(theme.Background).Background(theme.Primary).Bold(true)
+	if !focused {
+		selectedStyle = lipgloss.NewStyle().Foreground(theme.Primary).Reverse(true)
+	}
+
+	lines := []string{headerRow.Render(t.formatRow(headerCells, widths))}
+	for i := t.offset; i < len(t.rows) && i < t.offset+t.height; i++ {
+		style := rowStyle
+		if i == t.cursor {
+			style = selectedStyle
+		}
+		lines = append(lines, style.Render(t.formatRow(t.rows[i], widths)))
+	}
+
+	if len(t.rows) == 0 {
+		lines = append(lines, lipgloss.NewStyle().Foreground(theme.Dim).Render("No data"))
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,330 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+)
+
+// ThermalConfig moves the sysfs trees the Sensors page reads, for
+// containers that mount the host's /sys elsewhere
+type ThermalConfig struct {
+	HwmonRoot   string `json:"hwmon_root"`   // default /sys/class/hwmon
+	ThermalRoot string `json:"thermal_root"` // default /sys/class/thermal
+}
+
+const (
+	defaultHwmonRoot   = "/sys/class/hwmon"
+	defaultThermalRoot = "/sys/class/thermal"
+
+	// Temperatures without a critical trip point are judged against this
+	defaultCritTemp = 100.0
+	// coolTemp is where the reactor starts to warm up
+	coolTemp = 40.0
+)
+
+// tempReading is one temperature sensor, in degrees Celsius. High and
+// Crit are zero when the driver does not report them.
+type tempReading struct {
+	Chip    string  `json:"chip"`
+	Label   string  `json:"label"`
+	Celsius float64 `json:"celsius"`
+	High    float64 `json:"high,omitempty"`
+	Crit    float64 `json:"crit,omitempty"`
+}
+
+type fanReading struct {
+	Chip  string  `json:"chip"`
+	Label string  `json:"label"`
+	RPM   float64 `json:"rpm"`
+}
+
+type thermalMsg struct {
+	temps []tempReading
+	fans  []fanReading
+	err   error
+}
+
+// thermalReader reads hwmon chips and ACPI thermal zones from sysfs
+type thermalReader struct {
+	hwmonRoot, thermalRoot string
+}
+
+func newThermalReader(cfg *ThermalConfig) *thermalReader {
+	r := &thermalReader{hwmonRoot: defaultHwmonRoot, thermalRoot: defaultThermalRoot}
+	if cfg != nil && cfg.HwmonRoot != "" {
+		r.hwmonRoot = cfg.HwmonRoot
+	}
+	if cfg != nil && cfg.ThermalRoot != "" {
+		r.thermalRoot = cfg.ThermalRoot
+	}
+	return r
+}
+
+// read collects every sensor. It only fails when neither tree can be read;
+// a machine without sensors just reports none.
+func (r *thermalReader) read() thermalMsg {
+	var msg thermalMsg
+	chips, hwErr := r.readHwmon(&msg)
+	zoneErr := r.readThermalZones(&msg, chips)
+	if hwErr != nil && zoneErr != nil {
+		msg.err = hwErr
+	}
+	return msg
+}
+
+// readHwmon appends the temp*_input and fan*_input sensors of every chip
+// and returns the chip names it saw
+func (r *thermalReader) readHwmon(msg *thermalMsg) (map[string]bool, error) {
+	entries, err := os.ReadDir(r.hwmonRoot)
+	if err != nil {
+		return nil, err
+	}
+	chips := make(map[string]bool)
+	for _, e := range entries {
+		dir := filepath.Join(r.hwmonRoot, e.Name())
+		chip, ok := readSysfsString(filepath.Join(dir, "name"))
+		if !ok {
+			chip = e.Name()
+		}
+		chips[chip] = true
+
+		for _, n := range sensorIndexes(dir, "temp") {
+			prefix := filepath.Join(dir, "temp"+n)
+			milli, ok := readSysfsInt(prefix + "_input")
+			if !ok {
+				continue // some drivers expose sensors that always fail to read
+			}
+			t := tempReading{Chip: chip, Label: "temp" + n, Celsius: float64(milli) / 1000}
+			if label, ok := readSysfsString(prefix + "_label"); ok {
+				t.Label = label
+			}
+			if v, ok := readSysfsInt(prefix + "_max"); ok && v > 0 {
+				t.High = float64(v) / 1000
+			}
+			if v, ok := readSysfsInt(prefix + "_crit"); ok && v > 0 {
+				t.Crit = float64(v) / 1000
+			}
+			msg.temps = append(msg.temps, t)
+		}
+
+		for _, n := range sensorIndexes(dir, "fan") {
+			prefix := filepath.Join(dir, "fan"+n)
+			rpm, ok := readSysfsInt(prefix + "_input")
+			if !ok {
+				continue
+			}
+			f := fanReading{Chip: chip, Label: "fan" + n, RPM: float64(rpm)}
+			if label, ok := readSysfsString(prefix + "_label"); ok {
+				f.Label = label
+			}
+			msg.fans = append(msg.fans, f)
+		}
+	}
+	return chips, nil
+}
+
+// readThermalZones appends the thermal zones that hwmon did not already
+// report. The kernel registers most zones as hwmon chips too, named after
+// the zone type with dashes replaced.
+func (r *thermalReader) readThermalZones(msg *thermalMsg, chips map[string]bool) error {
+	zones, err := filepath.Glob(filepath.Join(r.thermalRoot, "thermal_zone*"))
+	if err != nil {
+		return err
+	}
+	if len(zones) == 0 {
+		if _, err := os.Stat(r.thermalRoot); err != nil {
+			return err
+		}
+	}
+	sort.Slice(zones, func(i, j int) bool { return zoneIndex(zones[i]) < zoneIndex(zones[j]) })
+
+	for _, dir := range zones {
+		typ, ok := readSysfsString(filepath.Join(dir, "type"))
+		if !ok || chips[strings.ReplaceAll(typ, "-", "_")] {
+			continue
+		}
+		milli, ok := readSysfsInt(filepath.Join(dir, "temp"))
+		if !ok {
+			continue
+		}
+		t := tempReading{Chip: filepath.Base(dir), Label: typ, Celsius: float64(milli) / 1000}
+
+		// Trip points give the zone's limits: "hot" warns, "critical" shuts down
+		trips, _ := filepath.Glob(filepath.Join(dir, "trip_point_*_type"))
+		for _, trip := range trips {
+			kind, _ := readSysfsString(trip)
+			v, ok := readSysfsInt(strings.TrimSuffix(trip, "_type") + "_temp")
+			if !ok || v <= 0 {
+				continue
+			}
+			switch kind {
+			case "hot":
+				t.High = float64(v) / 1000
+			case "critical":
+				t.Crit = float64(v) / 1000
+			}
+		}
+		msg.temps = append(msg.temps, t)
+	}
+	return nil
+}
+
+// sensorIndexes lists the N of every <kind>N_input file in dir, in numeric order
+func sensorIndexes(dir, kind string) []string {
+	inputs, _ := filepath.Glob(filepath.Join(dir, kind+"*_input"))
+	var idx []int
+	for _, in := range inputs {
+		n := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(in), kind), "_input")
+		if i, err := strconv.Atoi(n); err == nil {
+			idx = append(idx, i)
+		}
+	}
+	sort.Ints(idx)
+	out := make([]string, len(idx))
+	for i, n := range idx {
+		out[i] = strconv.Itoa(n)
+	}
+	return out
+}
+
+func zoneIndex(dir string) int {
+	n, _ := strconv.Atoi(strings.TrimPrefix(filepath.Base(dir), "thermal_zone"))
+	return n
+}
+
+func readSysfsString(path string) (string, bool) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+	s := strings.TrimSpace(string(b))
+	return s, s != ""
+}
+
+func readSysfsInt(path string) (int64, bool) {
+	s, ok := readSysfsString(path)
+	if !ok {
+		return 0, false
+	}
+	v, err := strconv.ParseInt(s, 10, 64)
+	return v, err == nil
+}
+
+func collectThermalCommand(r *thermalReader) tea.Cmd {
+	return func() tea.Msg {
+		return r.read()
+	}
+}
+
+// --- Model glue ---
+
+func (m *model) setThermal(msg thermalMsg) {
+	m.temps = msg.temps
+	m.fans = msg.fans
+	m.thermalErr = msg.err
+
+	rows := make([][]string, 0, len(msg.temps)+len(msg.fans))
+	for _, t := range msg.temps {
+		rows = append(rows, []string{t.Label, t.Chip, fmt.Sprintf("%.1f°C", t.Celsius), formatLimit(t.High), formatLimit(t.Crit)})
+	}
+	for _, f := range msg.fans {
+		rows = append(rows, []string{f.Label, f.Chip, fmt.Sprintf("%.0f RPM", f.RPM), "", ""})
+	}
+	m.sensorTable.setRows(rows)
+}
+
+func formatLimit(c float64) string {
+	if c == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.0f°C", c)
+}
+
+// hottest returns the sensor closest to its critical temperature
+func (m model) hottest() (tempReading, float64, bool) {
+	var hot tempReading
+	heat, found := -1.0, false
+	for _, t := range m.temps {
+		if h := t.heat(); h > heat || (h == heat && t.Celsius > hot.Celsius) {
+			hot, heat, found = t, h, true
+		}
+	}
+	return hot, max(heat, 0), found
+}
+
+// heat scales a reading from 0 at coolTemp to 1 at its critical point
+func (t tempReading) heat() float64 {
+	crit := t.Crit
+	if crit <= coolTemp {
+		crit = defaultCritTemp
+	}
+	return min(max((t.Celsius-coolTemp)/(crit-coolTemp), 0), 1)
+}
+
+// reactorHeat drives the arc reactor's pulse: 0 is idle, 1 is critical
+func (m model) reactorHeat() float64 {
+	_, heat, _ := m.hottest()
+	return heat
+}
+
+// reactorColors are the reactor's two pulse colors, shifting toward
+// yellow and then red as the hottest sensor heats up
+func (m model) reactorColors() (primary, accent lipgloss.Color) {
+	theme := m.getTheme()
+	switch heat := m.reactorHeat(); {
+	case heat >= 0.85:
+		return alertRed, alertYellow
+	case heat >= 0.6:
+		return theme.Primary, alertYellow
+	}
+	return theme.Primary, theme.Accent
+}
+
+// reactorReadout replaces the reactor's output line with the hottest
+// sensor's temperature. The column only fits a few cells, so the Sensors
+// page names the sensor.
+func (m model) reactorReadout() string {
+	theme := m.getTheme()
+	t, heat, ok := m.hottest()
+	if !ok {
+		return lipgloss.NewStyle().Foreground(theme.Dim).Render("PEAK:--")
+	}
+
+	color := theme.Dim
+	switch {
+	case heat >= 0.85:
+		color = alertRed
+	case heat >= 0.6:
+		color = alertYellow
+	}
+	return lipgloss.NewStyle().Foreground(color).Render(fmt.Sprintf("PEAK:%.0f°C", t.Celsius))
+}
+
+// renderSensorsPanel is a table panel whose header names the hottest sensor
+func (m model) renderSensorsPanel(width, height int) string {
+	title := "SENSORS"
+	if t, _, ok := m.hottest(); ok {
+		title += fmt.Sprintf(" · PEAK %.0f°C", t.Celsius)
+	}
+	if m.thermalErr != nil {
+		title += " · " + sensorError(m.thermalErr)
+	}
+	title = runewidth.Truncate(title, max(width-4, 1), "…")
+	return m.renderTablePanel(panelSensors, title, m.sensorTable, width, height)
+}
+
+func sensorError(err error) string {
+	if errors.Is(err, os.ErrNotExist) {
+		return "no hwmon or thermal sysfs"
+	}
+	return err.Error()
+}
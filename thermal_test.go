@@ -0,0 +1,118 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeTree creates files under root from a path -> content map
+func writeTree(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for path, content := range files {
+		full := filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func testThermal() thermalMsg {
+	return thermalMsg{
+		temps: []tempReading{
+			{Chip: "coretemp", Label: "Package id 0", Celsius: 93, High: 84, Crit: 100},
+			{Chip: "nvme", Label: "Composite", Celsius: 38.85, High: 81.85, Crit: 84.85},
+		},
+		fans: []fanReading{{Chip: "nct6798", Label: "CPU Fan", RPM: 1840}},
+	}
+}
+
+func TestThermalReader(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"hwmon/hwmon0/name":         "coretemp",
+		"hwmon/hwmon0/temp1_input":  "62000",
+		"hwmon/hwmon0/temp1_label":  "Package id 0",
+		"hwmon/hwmon0/temp1_max":    "84000",
+		"hwmon/hwmon0/temp1_crit":   "100000",
+		"hwmon/hwmon0/temp10_input": "58000",
+		"hwmon/hwmon0/temp2_input":  "55000",
+		"hwmon/hwmon0/temp3_input":  "", // unreadable sensors are skipped
+		"hwmon/hwmon1/name":         "nct6798",
+		"hwmon/hwmon1/fan1_input":   "1840",
+		"hwmon/hwmon1/fan1_label":   "CPU Fan",
+		"hwmon/hwmon1/fan2_input":   "0",
+		"hwmon/hwmon2/name":         "acpitz",
+		"hwmon/hwmon2/temp1_input":  "27800",
+
+		// acpitz duplicates hwmon2; the pch zone is only here
+		"thermal/thermal_zone0/type":              "acpitz",
+		"thermal/thermal_zone0/temp":              "27800",
+		"thermal/thermal_zone1/type":              "pch_cannonlake",
+		"thermal/thermal_zone1/temp":              "47000",
+		"thermal/thermal_zone1/trip_point_0_type": "critical",
+		"thermal/thermal_zone1/trip_point_0_temp": "115000",
+		"thermal/thermal_zone1/trip_point_1_type": "hot",
+		"thermal/thermal_zone1/trip_point_1_temp": "105000",
+	})
+
+	r := newThermalReader(&ThermalConfig{HwmonRoot: filepath.Join(root, "hwmon"), ThermalRoot: filepath.Join(root, "thermal")})
+	msg := r.read()
+	if msg.err != nil {
+		t.Fatal(msg.err)
+	}
+
+	var got []string
+	for _, tr := range msg.temps {
+		got = append(got, tr.Chip+"/"+tr.Label)
+	}
+	want := "coretemp/Package id 0, coretemp/temp2, coretemp/temp10, acpitz/temp1, thermal_zone1/pch_cannonlake"
+	if strings.Join(got, ", ") != want {
+		t.Errorf("temps = %s\nwant    %s", strings.Join(got, ", "), want)
+	}
+	if pkg := msg.temps[0]; pkg.Celsius != 62 || pkg.High != 84 || pkg.Crit != 100 {
+		t.Errorf("package = %+v", pkg)
+	}
+	if pch := msg.temps[4]; pch.High != 105 || pch.Crit != 115 {
+		t.Errorf("zone trip points = %+v", pch)
+	}
+	if len(msg.fans) != 2 || msg.fans[0].Label != "CPU Fan" || msg.fans[0].RPM != 1840 || msg.fans[1].Label != "fan2" {
+		t.Errorf("fans = %+v", msg.fans)
+	}
+
+	// Without either tree there is nothing to read
+	r = newThermalReader(&ThermalConfig{HwmonRoot: filepath.Join(root, "nope"), ThermalRoot: filepath.Join(root, "nope")})
+	if msg := r.read(); msg.err == nil || len(msg.temps) != 0 {
+		t.Errorf("missing roots: %+v", msg)
+	}
+}
+
+func TestReactorFollowsHottestSensor(t *testing.T) {
+	m := newTestModel(t, 120, 40)
+	if m.reactorHeat() != 0 || !strings.Contains(m.View(), "PEAK:--") {
+		t.Error("reactor without sensors should idle")
+	}
+
+	m = step(m, testThermal())
+	hot, heat, _ := m.hottest()
+	if hot.Label != "Package id 0" || heat < 0.88 || heat > 0.89 {
+		t.Errorf("hottest = %s at %v, want Package id 0 at 0.88", hot.Label, heat)
+	}
+	if primary, _ := m.reactorColors(); primary != alertRed {
+		t.Errorf("reactor at 93°C is %v, want red", primary)
+	}
+	if !strings.Contains(m.View(), "PEAK:93°C") {
+		t.Error("reactor readout does not show the hottest sensor")
+	}
+
+	// The pulse beats faster when hot
+	before := m.arcReactorPhase
+	m = step(m, tickMsg(testTime))
+	if got := m.arcReactorPhase - before; got < 0.27 || got > 0.28 {
+		t.Errorf("phase step = %v, want about 0.28", got)
+	}
+}
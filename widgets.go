@@ -0,0 +1,449 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+)
+
+// WidgetConfig describes a service widget: a JSON endpoint polled on an
+// interval, with one value picked out by a selector and rendered on the
+// Services page
+type WidgetConfig struct {
+	Name       string            `json:"name"`
+	URL        string            `json:"url"`
+	Selector   string            `json:"selector"` // e.g. "$.checks[0].latency_ms"; empty uses the whole body
+	Display    string            `json:"display"`  // "bar", "gauge", "badge" or "text" (default)
+	Unit       string            `json:"unit"`
+	Headers    map[string]string `json:"headers"`
+	Interval   Duration          `json:"interval"`    // default 10s
+	Timeout    Duration          `json:"timeout"`     // default 5s
+	StaleAfter Duration          `json:"stale_after"` // default three intervals
+
+	// Range of a bar or gauge; defaults to 0-100
+	Min *float64 `json:"min"`
+	Max *float64 `json:"max"`
+
+	// Numeric thresholds. When crit is below warn, lower values are worse.
+	Warn *float64 `json:"warn"`
+	Crit *float64 `json:"crit"`
+
+	// Healthy values for string results, e.g. ["UP", "ok"]; anything else is critical
+	OK []string `json:"ok"`
+}
+
+const (
+	defaultWidgetInterval = 10 * time.Second
+	defaultWidgetTimeout  = 5 * time.Second
+	maxWidgetBody         = 1 << 20
+)
+
+// widget is a configured widget plus its latest poll result
+type widget struct {
+	cfg     WidgetConfig
+	path    []selectorStep
+	client  *http.Client
+	value   any
+	err     error
+	updated time.Time // last successful poll
+	polled  bool
+}
+
+type widgetPollMsg struct{ idx int }
+
+type widgetResultMsg struct {
+	idx   int
+	value any
+	err   error
+}
+
+func newWidgets(cfgs []WidgetConfig) ([]widget, error) {
+	widgets := make([]widget, len(cfgs))
+	for i, cfg := range cfgs {
+		if cfg.Name == "" {
+			cfg.Name = fmt.Sprintf("WIDGET %d", i+1)
+		}
+		if !strings.HasPrefix(cfg.URL, "http://") && !strings.HasPrefix(cfg.URL, "https://") {
+			return nil, fmt.Errorf("widget %q: url must be http or https", cfg.Name)
+		}
+		switch cfg.Display {
+		case "", "text", "badge", "bar", "gauge":
+		default:
+			return nil, fmt.Errorf("widget %q: unknown display %q (want bar, gauge, badge or text)", cfg.Name, cfg.Display)
+		}
+		path, err := parseSelector(cfg.Selector)
+		if err != nil {
+			return nil, fmt.Errorf("widget %q: %w", cfg.Name, err)
+		}
+		if cfg.Interval <= 0 {
+			cfg.Interval = Duration(defaultWidgetInterval)
+		}
+		if cfg.Timeout <= 0 {
+			cfg.Timeout = Duration(defaultWidgetTimeout)
+		}
+		if cfg.StaleAfter <= 0 {
+			cfg.StaleAfter = 3 * cfg.Interval
+		}
+		widgets[i] = widget{
+			cfg:    cfg,
+			path:   path,
+			client: &http.Client{Timeout: time.Duration(cfg.Timeout)},
+		}
+	}
+	return widgets, nil
+}
+
+// --- Polling ---
+
+// widgetCommands starts polling every widget
+func (m model) widgetCommands() []tea.Cmd {
+	cmds := make([]tea.Cmd, len(m.widgets))
+	for i, w := range m.widgets {
+		cmds[i] = pollWidgetCommand(i, w)
+	}
+	return cmds
+}
+
+func pollWidgetCommand(idx int, w widget) tea.Cmd {
+	return func() tea.Msg {
+		v, err := w.fetch()
+		return widgetResultMsg{idx: idx, value: v, err: err}
+	}
+}
+
+func scheduleWidgetPoll(idx int, interval time.Duration) tea.Cmd {
+	return tea.Tick(interval, func(time.Time) tea.Msg {
+		return widgetPollMsg{idx: idx}
+	})
+}
+
+// fetch requests the widget's URL and applies its selector
+func (w widget) fetch() (any, error) {
+	req, err := http.NewRequest(http.MethodGet, w.cfg.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	for k, v := range w.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+
+	res, err := w.client.Do(req)
+	if err != nil {
+		var timeout interface{ Timeout() bool }
+		if errors.As(err, &timeout) && timeout.Timeout() {
+			return nil, fmt.Errorf("timed out after %s", time.Duration(w.cfg.Timeout))
+		}
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return nil, fmt.Errorf("HTTP %s", res.Status)
+	}
+
+	var body any
+	if err := json.NewDecoder(io.LimitReader(res.Body, maxWidgetBody)).Decode(&body); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	return evalSelector(body, w.path)
+}
+
+// setWidgetResult stores a poll result and schedules the next poll
+func (m *model) setWidgetResult(msg widgetResultMsg) tea.Cmd {
+	if msg.idx < 0 || msg.idx >= len(m.widgets) {
+		return nil
+	}
+	w := &m.widgets[msg.idx]
+	w.polled = true
+	w.err = msg.err
+	if msg.err == nil {
+		w.value = msg.value
+		w.updated = m.now()
+	}
+	return scheduleWidgetPoll(msg.idx, time.Duration(w.cfg.Interval))
+}
+
+// --- Selectors ---
+
+// selectorStep is one object key or array index in a selector path
+type selectorStep struct {
+	key   string
+	index int
+	isIdx bool
+}
+
+// parseSelector reads a JSONPath-like selector: an optional "$", then any
+// mix of .key, [index] (negative counts from the end) and ["quoted key"]
+func parseSelector(sel string) ([]selectorStep, error) {
+	s := strings.TrimPrefix(strings.TrimSpace(sel), "$")
+	var steps []selectorStep
+	for len(s) > 0 {
+		switch s[0] {
+		case '.':
+			s = s[1:]
+			fallthrough
+		default:
+			end := strings.IndexAny(s, ".[")
+			if end < 0 {
+				end = len(s)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("selector %q: empty key", sel)
+			}
+			steps = append(steps, selectorStep{key: s[:end]})
+			s = s[end:]
+		case '[':
+			end := strings.IndexByte(s, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("selector %q: missing ]", sel)
+			}
+			inner := s[1:end]
+			s = s[end+1:]
+			if len(inner) >= 2 && (inner[0] == '"' || inner[0] == '\'') && inner[len(inner)-1] == inner[0] {
+				steps = append(steps, selectorStep{key: inner[1 : len(inner)-1]})
+				continue
+			}
+			n, err := strconv.Atoi(inner)
+			if err != nil {
+				return nil, fmt.Errorf("selector %q: bad index %q", sel, inner)
+			}
+			steps = append(steps, selectorStep{index: n, isIdx: true})
+		}
+	}
+	return steps, nil
+}
+
+// evalSelector walks decoded JSON along path
+func evalSelector(v any, path []selectorStep) (any, error) {
+	for _, st := range path {
+		if st.isIdx {
+			arr, ok := v.([]any)
+			if !ok {
+				return nil, fmt.Errorf("[%d]: not an array", st.index)
+			}
+			i := st.index
+			if i < 0 {
+				i += len(arr)
+			}
+			if i < 0 || i >= len(arr) {
+				return nil, fmt.Errorf("[%d]: out of range (len %d)", st.index, len(arr))
+			}
+			v = arr[i]
+			continue
+		}
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%s: not an object", st.key)
+		}
+		if v, ok = obj[st.key]; !ok {
+			return nil, fmt.Errorf("%s: no such key", st.key)
+		}
+	}
+	return v, nil
+}
+
+// --- Status ---
+
+type widgetStatus int
+
+const (
+	widgetPending widgetStatus = iota
+	widgetOK
+	widgetWarn
+	widgetCrit
+	widgetStale
+	widgetError
+)
+
+// number converts a JSON value to a float for bars, gauges and thresholds
+func number(v any) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case bool:
+		return boolValue(v), true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func (w widget) status(now time.Time) widgetStatus {
+	switch {
+	case !w.polled:
+		return widgetPending
+	case w.updated.IsZero():
+		return widgetError
+	case now.Sub(w.updated) > time.Duration(w.cfg.StaleAfter):
+		return widgetStale
+	case w.err != nil:
+		// The last good value is still fresh; show it but flag the failure
+		return widgetWarn
+	}
+
+	if len(w.cfg.OK) > 0 {
+		s := fmt.Sprint(w.value)
+		for _, ok := range w.cfg.OK {
+			if strings.EqualFold(s, ok) {
+				return widgetOK
+			}
+		}
+		return widgetCrit
+	}
+
+	f, ok := number(w.value)
+	if !ok {
+		return widgetOK
+	}
+	lowerIsWorse := w.cfg.Warn != nil && w.cfg.Crit != nil && *w.cfg.Crit < *w.cfg.Warn
+	breached := func(limit *float64) bool {
+		if limit == nil {
+			return false
+		}
+		if lowerIsWorse {
+			return f <= *limit
+		}
+		return f >= *limit
+	}
+	switch {
+	case breached(w.cfg.Crit):
+		return widgetCrit
+	case breached(w.cfg.Warn):
+		return widgetWarn
+	}
+	return widgetOK
+}
+
+// fraction maps the value into the widget's bar/gauge range
+func (w widget) fraction() float64 {
+	f, ok := number(w.value)
+	if !ok {
+		return 0
+	}
+	lo, hi := 0.0, 100.0
+	if w.cfg.Min != nil {
+		lo = *w.cfg.Min
+	}
+	if w.cfg.Max != nil {
+		hi = *w.cfg.Max
+	}
+	if hi <= lo {
+		return 0
+	}
+	return min(max((f-lo)/(hi-lo), 0), 1)
+}
+
+// valueText formats the value with its unit
+func (w widget) valueText() string {
+	var s string
+	switch v := w.value.(type) {
+	case nil:
+		if !w.polled || w.updated.IsZero() {
+			return "—"
+		}
+		s = "null"
+	case float64:
+		s = strconv.FormatFloat(v, 'f', -1, 64)
+	case string:
+		s = v
+	case bool:
+		s = strconv.FormatBool(v)
+	default:
+		b, _ := json.Marshal(v)
+		s = string(b)
+	}
+	if w.cfg.Unit != "" {
+		s += " " + w.cfg.Unit
+	}
+	return s
+}
+
+// --- Rendering ---
+
+func (m model) renderWidgetsPanel(width, height int) string {
+	theme := m.getTheme()
+	contentWidth := max(width-2, 1)
+
+	blocks := []string{headerStyle.Render("SERVICE STATUS")}
+	if len(m.widgets) == 0 {
+		blocks = append(blocks, lipgloss.NewStyle().Foreground(theme.Dim).Width(contentWidth).
+			Render(`No widgets configured. Add a "widgets" list to the config file to poll JSON endpoints.`))
+	}
+	for _, w := range m.widgets {
+		blocks = append(blocks, m.renderWidget(w, contentWidth), "")
+	}
+
+	content := lipgloss.JoinVertical(lipgloss.Left, blocks...)
+	return m.panelStyle(panelServices).Width(width).Height(height).MaxHeight(height + 2).Render(content)
+}
+
+func (m model) renderWidget(w widget, width int) string {
+	theme := m.getTheme()
+	now := m.now()
+	st := w.status(now)
+
+	var badge string
+	switch st {
+	case widgetPending:
+		badge = lipgloss.NewStyle().Foreground(theme.Dim).Render("POLLING…")
+	case widgetOK:
+		badge = badgeGreen.Render("OK")
+	case widgetWarn:
+		badge = badgeYellow.Render("WARN")
+	case widgetCrit:
+		badge = badgeRed.Render("CRIT")
+	case widgetStale:
+		badge = lipgloss.NewStyle().Background(theme.Dim).Foreground(cDark).Padding(0, 1).
+			Render("STALE " + now.Sub(w.updated).Truncate(time.Second).String())
+	case widgetError:
+		badge = badgeRed.Render("ERROR")
+	}
+
+	name := lipgloss.NewStyle().Foreground(theme.Primary).Bold(true).
+		Render(runewidth.Truncate(w.cfg.Name, max(width-lipgloss.Width(badge)-1, 1), "…"))
+	gap := max(width-lipgloss.Width(name)-lipgloss.Width(badge), 1)
+	lines := []string{name + strings.Repeat(" ", gap) + badge}
+
+	text := w.valueText()
+	valueStyle := lipgloss.NewStyle().Foreground(theme.Secondary)
+	if st == widgetStale || st == widgetPending {
+		valueStyle = valueStyle.Foreground(theme.Dim)
+	}
+	switch w.cfg.Display {
+	case "bar":
+		barWidth := min(max(width-lipgloss.Width(text)-1, 4), 40)
+		lines = append(lines, valueStyle.Render(usageBar(w.fraction(), barWidth)+" "+text))
+	case "gauge":
+		lines = append(lines, m.renderCircularGauge(w.fraction(), max(min(width-8, 20), 4), text))
+	case "badge":
+		style := badgeGreen
+		switch st {
+		case widgetWarn:
+			style = badgeYellow
+		case widgetCrit, widgetError:
+			style = badgeRed
+		case widgetStale, widgetPending:
+			style = lipgloss.NewStyle().Background(theme.Dim).Foreground(cDark).Padding(0, 1)
+		}
+		lines = append(lines, style.Render(runewidth.Truncate(text, max(width-2, 1), "…")))
+	default:
+		lines = append(lines, valueStyle.Render(runewidth.Truncate(text, width, "…")))
+	}
+
+	if w.err != nil {
+		lines = append(lines, lipgloss.NewStyle().Foreground(alertRed).
+			Render(runewidth.Truncate("✗ "+w.err.Error(), width, "…")))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSelector(t *testing.T) {
+	var doc any
+	json.Unmarshal([]byte(`{
+		"status": "UP",
+		"checks": [{"name": "db", "latency_ms": 12.5}, {"name": "cache", "latency_ms": 3}],
+		"odd.key": {"x y": true}
+	}`), &doc)
+
+	for _, tc := range []struct {
+		sel  string
+		want any
+		err  string
+	}{
+		{sel: "", want: doc},
+		{sel: "$.status", want: "UP"},
+		{sel: "status", want: "UP"},
+		{sel: "$.checks[0].latency_ms", want: 12.5},
+		{sel: "checks[-1].name", want: "cache"},
+		{sel: `$["odd.key"]['x y']`, want: true},
+		{sel: "$.checks[2]", err: "out of range"},
+		{sel: "$.status.code", err: "not an object"},
+		{sel: "$.missing", err: "no such key"},
+		{sel: "$.status[0]", err: "not an array"},
+	} {
+		path, err := parseSelector(tc.sel)
+		if err != nil {
+			t.Fatalf("parseSelector(%q): %v", tc.sel, err)
+		}
+		got, err := evalSelector(doc, path)
+		if tc.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("%q: err = %v, want %q", tc.sel, err, tc.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", tc.sel, err)
+		} else if fmt.Sprint(got) != fmt.Sprint(tc.want) {
+			t.Errorf("%q = %v, want %v", tc.sel, got, tc.want)
+		}
+	}
+
+	for _, bad := range []string{"$.checks[", "$.checks[x]", "$..status"} {
+		if _, err := parseSelector(bad); err == nil {
+			t.Errorf("parseSelector(%q) accepted a bad selector", bad)
+		}
+	}
+}
+
+func TestWidgetStatus(t *testing.T) {
+	f := func(v float64) *float64 { return &v }
+	now := testTime
+
+	for _, tc := range []struct {
+		name string
+		cfg  WidgetConfig
+		w    widget
+		want widgetStatus
+	}{
+		{"pending", WidgetConfig{}, widget{}, widgetPending},
+		{"never succeeded", WidgetConfig{}, widget{polled: true, err: fmt.Errorf("boom")}, widgetError},
+		{"ok", WidgetConfig{Warn: f(70), Crit: f(90)}, widget{polled: true, value: 50.0, updated: now}, widgetOK},
+		{"warn", WidgetConfig{Warn: f(70), Crit: f(90)}, widget{polled: true, value: 75.0, updated: now}, widgetWarn},
+		{"crit", WidgetConfig{Warn: f(70), Crit: f(90)}, widget{polled: true, value: "95", updated: now}, widgetCrit},
+		{"lower is worse", WidgetConfig{Warn: f(20), Crit: f(10)}, widget{polled: true, value: 5.0, updated: now}, widgetCrit},
+		{"ok string", WidgetConfig{OK: []string{"up"}}, widget{polled: true, value: "UP", updated: now}, widgetOK},
+		{"bad string", WidgetConfig{OK: []string{"up"}}, widget{polled: true, value: "DOWN", updated: now}, widgetCrit},
+		{"failed poll after success", WidgetConfig{}, widget{polled: true, value: 1.0, updated: now, err: fmt.Errorf("boom")}, widgetWarn},
+		{"stale", WidgetConfig{}, widget{polled: true, value: 1.0, updated: now.Add(-time.Minute)}, widgetStale},
+	} {
+		cfgs, err := newWidgets([]WidgetConfig{{URL: "http://x", Warn: tc.cfg.Warn, Crit: tc.cfg.Crit, OK: tc.cfg.OK}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		w := tc.w
+		w.cfg = cfgs[0].cfg
+		if got := w.status(now); got != tc.want {
+			t.Errorf("%s: status = %d, want %d", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestWidgetPolling(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/health":
+			if r.Header.Get("X-Token") != "abc" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			fmt.Fprint(w, `{"status": {"load": 42.5}}`)
+		case "/slow":
+			time.Sleep(200 * time.Millisecond)
+			fmt.Fprint(w, `{}`)
+		case "/html":
+			fmt.Fprint(w, `<html>`)
+		}
+	}))
+	defer srv.Close()
+
+	widgets, err := newWidgets([]WidgetConfig{
+		{Name: "API", URL: srv.URL + "/health", Selector: "$.status.load", Headers: map[string]string{"X-Token": "abc"}},
+		{Name: "NOAUTH", URL: srv.URL + "/health"},
+		{Name: "SLOW", URL: srv.URL + "/slow", Timeout: Duration(50 * time.Millisecond)},
+		{Name: "HTML", URL: srv.URL + "/html"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := newTestModel(t, 120, 40)
+	m.widgets = widgets
+	for i, cmd := range m.widgetCommands() {
+		msg := cmd().(widgetResultMsg)
+		if msg.idx != i {
+			t.Fatalf("result for widget %d came back as %d", i, msg.idx)
+		}
+		m = step(m, msg)
+	}
+
+	if got := m.widgets[0].value; got != 42.5 {
+		t.Errorf("API value = %v, want 42.5", got)
+	}
+	for i, want := range []string{"", "403", "timed out", "invalid JSON"} {
+		err := m.widgets[i].err
+		if want == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error %v", m.widgets[i].cfg.Name, err)
+			}
+		} else if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: err = %v, want %q", m.widgets[i].cfg.Name, err, want)
+		}
+	}
+}
+
+func TestWidgetConfig(t *testing.T) {
+	for _, tc := range []struct {
+		cfg WidgetConfig
+		err string
+	}{
+		{WidgetConfig{URL: "ftp://x"}, "url must be http"},
+		{WidgetConfig{URL: "http://x", Display: "chart"}, "unknown display"},
+		{WidgetConfig{URL: "http://x", Selector: "$.a["}, "missing ]"},
+	} {
+		if _, err := newWidgets([]WidgetConfig{tc.cfg}); err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("%+v: err = %v, want %q", tc.cfg, err, tc.err)
+		}
+	}
+}